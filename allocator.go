@@ -0,0 +1,106 @@
+package ringbuffer
+
+import "sync"
+
+// Allocator 底层数组的分配器
+//
+// Alloc 返回长度为 n 的切片，Free 归还之前 Alloc 返回的切片，之后不能再访问该切片
+type Allocator interface {
+	Alloc(n int) []byte
+	Free(b []byte)
+}
+
+// NewWithAllocator 同 New，底层数组由 alloc 分配，扩容、缩容以及调用 Free 时归还给 alloc
+//
+// 除了放回 Pool 之后闲置的 RingBuffer，被 GC 回收时底层数组不会归还给 alloc：Peek、PeekAll 等零拷贝方法返回的切片在 RingBuffer 不可达之后可能依然在使用，
+// 不受 GC 管理的内存（例如 MmapAllocator）需要在不再使用 RingBuffer 以及返回的切片之后显式调用 Free，
+// 否则会泄漏；在此之前需要保证 RingBuffer 可达，必要时使用 runtime.KeepAlive
+func NewWithAllocator(size int, alloc Allocator) *RingBuffer {
+	r := &RingBuffer{
+		initSize: size,
+		size:     size,
+		isEmpty:  true,
+		vEmpty:   true,
+		refs:     1,
+		alloc:    alloc,
+	}
+	r.buf = r.allocBuf(size)
+	return r
+}
+
+// Free 将底层数组归还给 Allocator，并归还在 Budget 中预留的内存，之后 r 变为容量为 0 的空缓冲区
+// 之前通过 Peek 等方法得到的切片不能再访问
+func (r *RingBuffer) Free() {
+	r.checkUse()
+	if r.budget != nil {
+		r.budget.Release(int64(len(r.buf)))
+	}
+	r.freeBuf(r.buf)
+	r.buf = nil
+	r.size = 0
+	r.initSize = 0
+	r.r, r.w, r.vr = 0, 0, 0
+	r.isEmpty = true
+	r.vEmpty = true
+	r.lastRead = opInvalid
+}
+
+// HeapAllocator 使用 Go 堆内存，Free 什么也不做
+type HeapAllocator struct{}
+
+// Alloc 使用 make 申请内存
+func (HeapAllocator) Alloc(n int) []byte {
+	return make([]byte, n)
+}
+
+// Free 交给 GC 回收
+func (HeapAllocator) Free(b []byte) {}
+
+// SlabAllocator 按 2 的幂划分 size class，相同 size class 的内存块通过 sync.Pool 复用
+type SlabAllocator struct {
+	minBitSize int
+	classes    []sync.Pool
+}
+
+// NewSlabAllocator 创建 size class 从 minSize 到 maxSize（向上取整到 2 的幂）的 SlabAllocator
+// 超过 maxSize 的内存直接使用 make 申请，不会被复用
+func NewSlabAllocator(minSize, maxSize int) *SlabAllocator {
+	minBits, maxBits := bitSize(minSize), bitSize(maxSize)
+	if maxBits < minBits {
+		maxBits = minBits
+	}
+	return &SlabAllocator{
+		minBitSize: minBits,
+		classes:    make([]sync.Pool, maxBits-minBits+1),
+	}
+}
+
+// Alloc 从 n 对应的 size class 中分配，返回的切片长度为 n，容量为 size class 的大小
+func (a *SlabAllocator) Alloc(n int) []byte {
+	idx := index(n, a.minBitSize, len(a.classes))
+	size := 1 << uint(a.minBitSize+idx)
+	if n > size {
+		return make([]byte, n)
+	}
+
+	if v := a.classes[idx].Get(); v != nil {
+		b := *(v.(*[]byte))
+		return b[:n]
+	}
+	return make([]byte, n, size)
+}
+
+// Free 将容量恰好为某个 size class 大小的切片放回对应的池中
+func (a *SlabAllocator) Free(b []byte) {
+	c := cap(b)
+	if c < 1<<uint(a.minBitSize) {
+		return
+	}
+	idx := index(c, a.minBitSize, len(a.classes))
+	if 1<<uint(a.minBitSize+idx) != c {
+		return
+	}
+
+	b = b[:c]
+	a.classes[idx].Put(&b)
+}
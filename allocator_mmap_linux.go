@@ -0,0 +1,44 @@
+package ringbuffer
+
+import "syscall"
+
+// MmapAllocator 使用匿名 mmap 分配内存，不受 GC 管理，适合非常大的缓冲区
+//
+// Free 之后内存会被立即 munmap，之前通过 Peek 等方法得到的切片不能再访问
+// GC 不会归还这部分内存，使用完毕后需要调用 RingBuffer.Free
+type MmapAllocator struct {
+	hugePageThreshold int
+}
+
+// NewMmapAllocator 创建 MmapAllocator，不小于 hugePageThreshold 的内存会使用 MADV_HUGEPAGE 建议内核使用透明大页，
+// hugePageThreshold 为 0 时不使用大页
+func NewMmapAllocator(hugePageThreshold int) *MmapAllocator {
+	return &MmapAllocator{hugePageThreshold: hugePageThreshold}
+}
+
+// Alloc 使用 mmap 分配 n 字节，失败时 panic
+func (a *MmapAllocator) Alloc(n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+
+	pageSize := syscall.Getpagesize()
+	length := (n + pageSize - 1) / pageSize * pageSize
+	b, err := syscall.Mmap(-1, 0, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		panic("ringbuffer: mmap failed: " + err.Error())
+	}
+	if a.hugePageThreshold > 0 && length >= a.hugePageThreshold {
+		// 仅是建议，内核不支持时忽略错误
+		_ = syscall.Madvise(b, syscall.MADV_HUGEPAGE)
+	}
+	return b[:n]
+}
+
+// Free munmap 之前 Alloc 返回的内存
+func (a *MmapAllocator) Free(b []byte) {
+	if cap(b) == 0 {
+		return
+	}
+	_ = syscall.Munmap(b[:cap(b)])
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+// +build !linux
+
+package ringbuffer
+
+// MmapAllocator 在非 Linux 平台上退化为使用 Go 堆内存
+type MmapAllocator struct {
+	hugePageThreshold int
+}
+
+// NewMmapAllocator 创建 MmapAllocator，非 Linux 平台上 hugePageThreshold 被忽略
+func NewMmapAllocator(hugePageThreshold int) *MmapAllocator {
+	return &MmapAllocator{hugePageThreshold: hugePageThreshold}
+}
+
+// Alloc 使用 make 申请内存
+func (a *MmapAllocator) Alloc(n int) []byte {
+	return make([]byte, n)
+}
+
+// Free 交给 GC 回收
+func (a *MmapAllocator) Free(b []byte) {}
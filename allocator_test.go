@@ -0,0 +1,173 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingAllocator 记录 Alloc 和 Free 的调用
+type countingAllocator struct {
+	allocs, frees []int
+}
+
+func (a *countingAllocator) Alloc(n int) []byte {
+	a.allocs = append(a.allocs, n)
+	return make([]byte, n)
+}
+
+func (a *countingAllocator) Free(b []byte) {
+	a.frees = append(a.frees, len(b))
+}
+
+func TestNewWithAllocator(t *testing.T) {
+	a := &countingAllocator{}
+	rb := NewWithAllocator(4, a)
+
+	_, _ = rb.Write([]byte("abcdef"))
+	rb.Reset()
+	rb.WithData([]byte("xyz"))
+	rb.Reset()
+
+	if len(a.allocs) != 3 || a.allocs[0] != 4 || a.allocs[1] != 8 || a.allocs[2] != 4 {
+		t.Fatalf("unexpected allocs %v", a.allocs)
+	}
+	// WithData 之后不再使用 Allocator
+	if len(a.frees) != 3 || a.frees[0] != 4 || a.frees[1] != 8 || a.frees[2] != 4 {
+		t.Fatalf("unexpected frees %v", a.frees)
+	}
+
+	p := NewPool(PoolOptions{DefaultSize: 16, Allocator: a})
+	_ = p.Get()
+	if len(a.allocs) != 4 || a.allocs[3] != 16 {
+		t.Fatalf("unexpected allocs %v", a.allocs)
+	}
+}
+
+func TestSlabAllocator(t *testing.T) {
+	a := NewSlabAllocator(64, 1024)
+
+	b := a.Alloc(100)
+	if len(b) != 100 || cap(b) != 128 {
+		t.Fatalf("expect len 100 cap 128 but got %d %d", len(b), cap(b))
+	}
+	a.Free(b)
+
+	for i := 0; i < 10; i++ {
+		b = a.Alloc(65)
+		if len(b) != 65 || cap(b) != 128 {
+			t.Fatalf("expect len 65 cap 128 but got %d %d", len(b), cap(b))
+		}
+		a.Free(b)
+	}
+
+	if b = a.Alloc(5000); len(b) != 5000 {
+		t.Fatalf("expect len 5000 but got %d", len(b))
+	}
+	a.Free(b)
+	a.Free(make([]byte, 100))
+
+	rb := NewWithAllocator(64, a)
+	_, _ = rb.WriteString(strings.Repeat("a", 100))
+	if rb.Capacity() != 128 || rb.Length() != 100 {
+		t.Fatalf("unexpected cap %d len %d", rb.Capacity(), rb.Length())
+	}
+}
+
+func TestMmapAllocator(t *testing.T) {
+	a := NewMmapAllocator(1 << 21)
+
+	rb := NewWithAllocator(1<<20, a)
+	data := bytes.Repeat([]byte("0123456789"), 300000)
+	_, _ = rb.Write(data)
+	if !bytes.Equal(rb.Bytes(), data) {
+		t.Fatal("unexpected data")
+	}
+	rb.Reset()
+	if rb.Capacity() != 1<<20 {
+		t.Fatalf("expect capacity %d but got %d", 1<<20, rb.Capacity())
+	}
+	rb.Free()
+
+	if b := a.Alloc(0); b != nil {
+		t.Fatal("expect nil")
+	}
+}
+
+func TestRingBuffer_Free(t *testing.T) {
+	a := &countingAllocator{}
+	budget := NewBudget(64, false)
+	rb := NewWithAllocator(16, a)
+	if err := rb.SetBudget(budget); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = rb.WriteString("abc")
+
+	rb.Free()
+	if len(a.frees) != 1 || a.frees[0] != 16 {
+		t.Fatalf("unexpected frees %v", a.frees)
+	}
+	if rb.Capacity() != 0 || !rb.IsEmpty() || budget.Stats().Reserved != 0 {
+		t.Fatalf("unexpected cap %d len %d reserved %d", rb.Capacity(), rb.Length(), budget.Stats().Reserved)
+	}
+
+	// 被 GC 回收时不会归还给 Allocator，之前返回的切片依然可用
+	a = &countingAllocator{}
+	first := func() []byte {
+		rb := NewWithAllocator(16, a)
+		_, _ = rb.WriteString("zero copy")
+		first, _ := rb.PeekAll()
+		return first
+	}()
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+	}
+	if len(a.frees) != 0 || string(first) != "zero copy" {
+		t.Fatalf("unexpected frees %v, data %q", a.frees, first)
+	}
+}
+
+// lockedAllocator 记录 Free 的次数，finalizer 在其他 goroutine 中调用 Free
+type lockedAllocator struct {
+	mu    sync.Mutex
+	frees int
+}
+
+func (a *lockedAllocator) Alloc(n int) []byte {
+	return make([]byte, n)
+}
+
+func (a *lockedAllocator) Free(b []byte) {
+	a.mu.Lock()
+	a.frees++
+	a.mu.Unlock()
+}
+
+func TestPool_AllocatorEvicted(t *testing.T) {
+	if DebugEnabled {
+		t.Skip("buffers are not reused after Put in debug builds")
+	}
+
+	a := &lockedAllocator{}
+	p := NewPool(PoolOptions{DefaultSize: 64, Allocator: a})
+	for i := 0; i < 10; i++ {
+		p.Put(p.Get())
+	}
+
+	// sync.Pool 在两次 GC 之后清理闲置的 RingBuffer，finalizer 归还底层数组
+	for i := 0; i < 20; i++ {
+		runtime.GC()
+		a.mu.Lock()
+		frees := a.frees
+		a.mu.Unlock()
+		if frees > 0 {
+			runtime.KeepAlive(p)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expect evicted buffers freed")
+}
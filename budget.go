@@ -0,0 +1,173 @@
+package ringbuffer
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrBudgetExceeded 内存预算不足
+var ErrBudgetExceeded = errors.New("ring buffer: memory budget exceeded")
+
+// Budget 多个 RingBuffer 共享的内存预算，限制底层数组的总大小
+//
+// 关联了 Budget 的 RingBuffer 在新建、扩容时预留内存，缩容以及被 GC 回收时归还
+type Budget struct {
+	limit int64
+	block bool
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	reserved int64
+	peak     int64
+	rejected uint64
+	waited   uint64
+}
+
+// BudgetStats Budget 的统计信息
+type BudgetStats struct {
+	// Limit 预算上限
+	Limit int64
+	// Reserved 当前已经预留的字节数
+	Reserved int64
+	// Peak 预留字节数的峰值
+	Peak int64
+	// Rejected 因为预算不足而失败的预留次数
+	Rejected uint64
+	// Waited 因为预算不足而阻塞等待的次数
+	Waited uint64
+}
+
+// NewBudget 创建一个上限为 limit 字节的 Budget
+// block 为 true 时预算不足会阻塞等待其他 RingBuffer 归还，否则返回 ErrBudgetExceeded
+func NewBudget(limit int64, block bool) *Budget {
+	b := &Budget{limit: limit, block: block}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Reserve 预留 n 字节，超过上限的单次预留总是返回 ErrBudgetExceeded
+func (b *Budget) Reserve(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n > b.limit {
+		b.rejected++
+		return ErrBudgetExceeded
+	}
+	if b.reserved+n > b.limit {
+		if !b.block {
+			b.rejected++
+			return ErrBudgetExceeded
+		}
+		b.waited++
+		for b.reserved+n > b.limit {
+			b.cond.Wait()
+		}
+	}
+
+	b.reserved += n
+	if b.reserved > b.peak {
+		b.peak = b.reserved
+	}
+	return nil
+}
+
+// add 不检查上限直接预留 n 字节
+func (b *Budget) add(n int64) {
+	b.mu.Lock()
+	b.reserved += n
+	if b.reserved > b.peak {
+		b.peak = b.reserved
+	}
+	b.mu.Unlock()
+}
+
+// Release 归还 n 字节
+func (b *Budget) Release(n int64) {
+	if n <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	b.reserved -= n
+	if b.reserved < 0 {
+		b.reserved = 0
+	}
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// Stats 返回 Budget 的统计信息
+func (b *Budget) Stats() BudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BudgetStats{
+		Limit:    b.limit,
+		Reserved: b.reserved,
+		Peak:     b.peak,
+		Rejected: b.rejected,
+		Waited:   b.waited,
+	}
+}
+
+// NewWithBudget 同 New，底层数组的内存从 budget 中预留
+func NewWithBudget(size int, budget *Budget) (*RingBuffer, error) {
+	if err := budget.Reserve(int64(size)); err != nil {
+		return nil, err
+	}
+
+	r := New(size)
+	r.budget = budget
+	r.ensureFinalizer()
+	return r, nil
+}
+
+// SetBudget 为 r 关联 budget，并为当前的底层数组预留内存，之前关联的 Budget 会被归还
+func (r *RingBuffer) SetBudget(budget *Budget) error {
+	r.checkUse()
+	if budget == r.budget {
+		return nil
+	}
+	if budget != nil {
+		if err := budget.Reserve(int64(len(r.buf))); err != nil {
+			return err
+		}
+	}
+	if r.budget != nil {
+		r.budget.Release(int64(len(r.buf)))
+	}
+
+	r.budget = budget
+	if budget != nil {
+		r.ensureFinalizer()
+	}
+	return nil
+}
+
+// ensureFinalizer 为 r 设置 finalizer，每个 RingBuffer 只会设置一次
+func (r *RingBuffer) ensureFinalizer() {
+	if r.finalizer {
+		return
+	}
+	r.finalizer = true
+	runtime.SetFinalizer(r, finalize)
+}
+
+// finalize 归还 Budget 中预留的内存
+// 在 Pool 中闲置的 RingBuffer 没有还在使用的切片，底层数组归还给 Allocator；
+// 其他情况下零拷贝方法返回的切片此时可能依然在使用，底层数组不会归还，见 NewWithAllocator
+func finalize(r *RingBuffer) {
+	debugFinalize(r)
+	if r.budget != nil {
+		r.budget.Release(int64(len(r.buf)))
+	}
+	if r.pooled {
+		r.freeBuf(r.buf)
+	}
+}
@@ -0,0 +1,87 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget(100, false)
+
+	rb, err := NewWithBudget(32, budget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewWithBudget(80, budget); err != ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+
+	// 32 -> 64
+	if _, err = rb.Write(make([]byte, 40)); err != nil {
+		t.Fatal(err)
+	}
+	if s := budget.Stats(); s.Reserved != 64 || s.Peak != 64 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	// 64 -> 128 超过预算，数据不变
+	n, err := rb.Write(make([]byte, 40))
+	if n != 0 || err != ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %d, %v", n, err)
+	}
+	if rb.Length() != 40 || rb.Capacity() != 64 {
+		t.Fatalf("unexpected len %d, cap %d", rb.Length(), rb.Capacity())
+	}
+	if err = rb.WriteByte(1); err != nil {
+		t.Fatal(err)
+	}
+
+	rb.Reset()
+	if s := budget.Stats(); s.Reserved != 32 || s.Rejected != 2 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	other := New(50)
+	if err = other.SetBudget(budget); err != nil {
+		t.Fatal(err)
+	}
+	if err = other.SetBudget(nil); err != nil {
+		t.Fatal(err)
+	}
+	if s := budget.Stats(); s.Reserved != 32 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestBudget_Block(t *testing.T) {
+	budget := NewBudget(64, true)
+	if err := budget.Reserve(64); err != nil {
+		t.Fatal(err)
+	}
+	if err := budget.Reserve(65); err != ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		rb, err := NewWithBudget(16, budget)
+		if err == nil && rb.Capacity() != 16 {
+			t.Errorf("unexpected capacity %d", rb.Capacity())
+		}
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expect blocking")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	budget.Release(32)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s := budget.Stats(); s.Reserved != 48 || s.Waited != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+var (
+	// LineDelimiters 以 "\r\n" 或 "\n" 分隔
+	LineDelimiters = [][]byte{[]byte("\r\n"), []byte("\n")}
+	// NulDelimiter 以 '\0' 分隔
+	NulDelimiter = [][]byte{{0}}
+)
+
+// DelimiterDecoder 按分隔符拆包，参考 Netty 的 DelimiterBasedFrameDecoder
+//
+// DelimiterDecoder 带有状态，每个连接需要使用单独的实例
+type DelimiterDecoder struct {
+	delimiters     [][]byte
+	maxDelimLength int
+	maxFrameLength int
+	stripDelimiter bool
+
+	discarding bool
+}
+
+// NewDelimiterDecoder 创建 DelimiterDecoder
+// maxFrameLength 为不包含分隔符的最大帧长度，不大于 0 时不限制（与 LengthFieldDecoder.MaxFrameLength 一致），
+// stripDelimiter 为 true 时返回的帧不包含分隔符
+func NewDelimiterDecoder(maxFrameLength int, stripDelimiter bool, delimiters ...[]byte) *DelimiterDecoder {
+	if len(delimiters) == 0 {
+		panic("codec: empty delimiters")
+	}
+
+	d := &DelimiterDecoder{
+		maxFrameLength: maxFrameLength,
+		stripDelimiter: stripDelimiter,
+	}
+	for _, delim := range delimiters {
+		if len(delim) == 0 {
+			panic("codec: empty delimiter")
+		}
+		if len(delim) > d.maxDelimLength {
+			d.maxDelimLength = len(delim)
+		}
+		d.delimiters = append(d.delimiters, append([]byte(nil), delim...))
+	}
+	return d
+}
+
+// Decode 从 rb 中取出一个以分隔符结尾的帧，并移动读指针
+// 数据不足一帧时返回 nil, nil
+// 帧长度超过 maxFrameLength 时丢弃该帧并返回 *TooLongFrameError，
+// 若此时还没有收到分隔符，之后的数据会被持续丢弃直到遇到分隔符，避免缓冲区无限增长
+func (d *DelimiterDecoder) Decode(rb *ringbuffer.RingBuffer) (frame []byte, err error) {
+	idx, delimLength := d.indexDelimiter(rb)
+
+	if d.discarding {
+		if idx < 0 {
+			d.discard(rb)
+			return nil, nil
+		}
+		rb.Retrieve(idx + delimLength)
+		d.discarding = false
+		idx, delimLength = d.indexDelimiter(rb)
+	}
+
+	if idx < 0 {
+		if d.tooLong(rb.Length()) {
+			length := rb.Length()
+			d.discarding = true
+			d.discard(rb)
+			return nil, &TooLongFrameError{FrameLength: uint64(length), MaxFrameLength: d.maxFrameLength}
+		}
+		return nil, nil
+	}
+
+	if d.tooLong(idx) {
+		rb.Retrieve(idx + delimLength)
+		return nil, &TooLongFrameError{FrameLength: uint64(idx), MaxFrameLength: d.maxFrameLength}
+	}
+
+	if d.stripDelimiter {
+		frame = make([]byte, idx)
+		_, _ = rb.Read(frame)
+		rb.Retrieve(delimLength)
+	} else {
+		frame = make([]byte, idx+delimLength)
+		_, _ = rb.Read(frame)
+	}
+	return
+}
+
+// tooLong 长度为 n 的帧是否超过 maxFrameLength
+func (d *DelimiterDecoder) tooLong(n int) bool {
+	return d.maxFrameLength > 0 && n > d.maxFrameLength
+}
+
+// indexDelimiter 返回最靠前的分隔符的偏移及其长度，偏移相同时优先较长的分隔符
+func (d *DelimiterDecoder) indexDelimiter(rb *ringbuffer.RingBuffer) (idx int, delimLength int) {
+	idx = -1
+	for _, delim := range d.delimiters {
+		i := rb.Index(delim)
+		if i < 0 {
+			continue
+		}
+		if idx < 0 || i < idx || (i == idx && len(delim) > delimLength) {
+			idx = i
+			delimLength = len(delim)
+		}
+	}
+	return
+}
+
+// discard 丢弃已缓存的数据，保留末尾可能是分隔符前缀的部分
+func (d *DelimiterDecoder) discard(rb *ringbuffer.RingBuffer) {
+	rb.Retrieve(rb.Length() - (d.maxDelimLength - 1))
+}
@@ -0,0 +1,96 @@
+package codec
+
+import (
+	"testing"
+)
+
+func TestDelimiterDecoder_Decode(t *testing.T) {
+	d := NewDelimiterDecoder(16, true, LineDelimiters...)
+	rb := newWrapped(16, 10)
+	_, _ = rb.Write([]byte("PING\r\nPONG\nQU"))
+
+	for _, expect := range []string{"PING", "PONG"} {
+		frame, err := d.Decode(rb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(frame) != expect {
+			t.Fatalf("expect %q but got %q", expect, frame)
+		}
+	}
+
+	frame, err := d.Decode(rb)
+	if frame != nil || err != nil {
+		t.Fatalf("expect nil frame but got %q, err %v", frame, err)
+	}
+	if rb.Length() != 2 {
+		t.Fatalf("expect len 2 bytes but got %d", rb.Length())
+	}
+
+	d = NewDelimiterDecoder(16, false, NulDelimiter...)
+	_, _ = rb.Write([]byte("IT\x00"))
+	frame, err = d.Decode(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(frame) != "QUIT\x00" {
+		t.Fatalf("expect QUIT\\0 but got %q", frame)
+	}
+}
+
+func TestDelimiterDecoder_TooLong(t *testing.T) {
+	d := NewDelimiterDecoder(4, true, []byte("\r\n"))
+	rb := newWrapped(8, 5)
+
+	_, _ = rb.Write([]byte("abcdef\r"))
+	_, err := d.Decode(rb)
+	if e, ok := err.(*TooLongFrameError); !ok || e.FrameLength != 7 {
+		t.Fatalf("expect *TooLongFrameError but got %v", err)
+	}
+	// 保留可能是分隔符前缀的 '\r'
+	if rb.Length() != 1 {
+		t.Fatalf("expect len 1 bytes but got %d", rb.Length())
+	}
+
+	_, _ = rb.Write([]byte("gh"))
+	frame, err := d.Decode(rb)
+	if frame != nil || err != nil {
+		t.Fatalf("expect nil frame but got %q, err %v", frame, err)
+	}
+
+	_, _ = rb.Write([]byte("\r\nok\r\n"))
+	frame, err = d.Decode(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(frame) != "ok" {
+		t.Fatalf("expect ok but got %q", frame)
+	}
+
+	_, _ = rb.Write([]byte("12345\r\nok\r\n"))
+	_, err = d.Decode(rb)
+	if e, ok := err.(*TooLongFrameError); !ok || e.FrameLength != 5 {
+		t.Fatalf("expect *TooLongFrameError but got %v", err)
+	}
+	frame, err = d.Decode(rb)
+	if err != nil || string(frame) != "ok" {
+		t.Fatalf("expect ok but got %q, err %v", frame, err)
+	}
+}
+
+func TestDelimiterDecoder_Unlimited(t *testing.T) {
+	d := NewDelimiterDecoder(0, true, LineDelimiters...)
+	rb := newWrapped(8, 5)
+
+	_, _ = rb.Write([]byte("abc"))
+	frame, err := d.Decode(rb)
+	if frame != nil || err != nil || rb.Length() != 3 {
+		t.Fatalf("expect nil frame but got %q, err %v, len %d", frame, err, rb.Length())
+	}
+
+	_, _ = rb.Write([]byte("defghijklmn\n"))
+	frame, err = d.Decode(rb)
+	if err != nil || string(frame) != "abcdefghijklmn" {
+		t.Fatalf("expect abcdefghijklmn but got %q, err %v", frame, err)
+	}
+}
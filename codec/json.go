@@ -0,0 +1,179 @@
+package codec
+
+import (
+	"errors"
+	"io"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+// ErrInvalidJSON JSON 值的起始字符非法
+var ErrInvalidJSON = errors.New("codec: invalid JSON value")
+
+// JSONSplitter 在首尾相接的 JSON 流中找出每个 JSON 值的边界，再交给 encoding/json 解析
+//
+// JSONSplitter 会记录已经扫描过的位置以及字符串、转义、嵌套状态，数据分多次到达时不会重复扫描。
+// JSONSplitter 带有状态，每个连接需要使用单独的实例
+type JSONSplitter struct {
+	// MaxValueLength 单个值（包含前导空白）的最大长度，为 0 时不限制
+	MaxValueLength int
+
+	offset  int
+	started bool
+	depth   int
+	str     bool   // 在字符串中
+	escape  bool   // 字符串中上一个字符是 '\\'
+	number  bool   // 顶层的数字
+	literal string // 顶层的 true/false/null
+	matched int    // literal 已经匹配的字节数
+}
+
+// ValueLength 返回 rb 中下一个完整 JSON 值的长度（包含前导空白），值不完整时返回 0
+// 超过 MaxValueLength 时返回 *TooLongFrameError，调用者应当关闭连接
+// 返回值大于 0 时，调用者需要从 rb 中取走这些字节，JSONSplitter 的状态会被重置
+// 顶层的数字只有在之后出现其他字符时才能确定结尾，流结束时使用 ValueLengthAtEOF
+func (s *JSONSplitter) ValueLength(rb *ringbuffer.RingBuffer) (int, error) {
+	return s.valueLength(rb, false)
+}
+
+// ValueLengthAtEOF 与 ValueLength 相同，但是将 rb 中数据的结尾视为流的结尾：
+// 末尾的顶层数字会被当作完整的值返回，不完整的值返回 io.ErrUnexpectedEOF
+func (s *JSONSplitter) ValueLengthAtEOF(rb *ringbuffer.RingBuffer) (int, error) {
+	return s.valueLength(rb, true)
+}
+
+func (s *JSONSplitter) valueLength(rb *ringbuffer.RingBuffer, atEOF bool) (int, error) {
+	if s.offset > rb.Length() {
+		s.Reset()
+	}
+
+	first, end := rb.PeekAt(s.offset, rb.Length()-s.offset)
+	n, found, err := s.scan(first)
+	if !found && err == nil {
+		n, found, err = s.scan(end)
+	}
+	if err != nil {
+		s.Reset()
+		return 0, err
+	}
+
+	if !found {
+		n = s.offset
+		if atEOF && s.started {
+			if !s.number {
+				s.Reset()
+				return 0, io.ErrUnexpectedEOF
+			}
+			found = true
+		}
+	}
+	if s.MaxValueLength > 0 && n > s.MaxValueLength {
+		s.Reset()
+		return 0, &TooLongFrameError{FrameLength: uint64(n), MaxFrameLength: s.MaxValueLength}
+	}
+	if !found {
+		return 0, nil
+	}
+
+	s.Reset()
+	return n, nil
+}
+
+// Decode 从 rb 中取出下一个完整的 JSON 值并移动读指针，值不完整时返回 nil, nil
+func (s *JSONSplitter) Decode(rb *ringbuffer.RingBuffer) ([]byte, error) {
+	return s.decode(rb, false)
+}
+
+// DecodeAtEOF 与 Decode 相同，但是将 rb 中数据的结尾视为流的结尾，见 ValueLengthAtEOF
+func (s *JSONSplitter) DecodeAtEOF(rb *ringbuffer.RingBuffer) ([]byte, error) {
+	return s.decode(rb, true)
+}
+
+func (s *JSONSplitter) decode(rb *ringbuffer.RingBuffer, atEOF bool) ([]byte, error) {
+	n, err := s.valueLength(rb, atEOF)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+
+	b := make([]byte, n)
+	_, _ = rb.Read(b)
+	return b, nil
+}
+
+// Reset 重置扫描状态
+func (s *JSONSplitter) Reset() {
+	*s = JSONSplitter{MaxValueLength: s.MaxValueLength}
+}
+
+// scan 扫描 b 并更新状态，找到值的结尾时返回值的长度
+func (s *JSONSplitter) scan(b []byte) (n int, found bool, err error) {
+	for i, c := range b {
+		switch {
+		case !s.started:
+			switch {
+			case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			case c == '{' || c == '[':
+				s.depth = 1
+			case c == '"':
+				s.str = true
+			case c == '-' || (c >= '0' && c <= '9'):
+				s.number = true
+			case c == 't':
+				s.literal = "true"
+			case c == 'f':
+				s.literal = "false"
+			case c == 'n':
+				s.literal = "null"
+			default:
+				return 0, false, ErrInvalidJSON
+			}
+			if s.literal != "" {
+				s.matched = 1
+			}
+			s.started = s.depth > 0 || s.str || s.number || s.literal != ""
+		case s.str:
+			switch {
+			case s.escape:
+				s.escape = false
+			case c == '\\':
+				s.escape = true
+			case c == '"':
+				s.str = false
+				if s.depth == 0 {
+					return s.offset + i + 1, true, nil
+				}
+			}
+		case s.number:
+			if !isNumberByte(c) {
+				return s.offset + i, true, nil
+			}
+		case s.literal != "":
+			if c != s.literal[s.matched] {
+				return 0, false, ErrInvalidJSON
+			}
+			s.matched++
+			if s.matched == len(s.literal) {
+				return s.offset + i + 1, true, nil
+			}
+		default:
+			switch c {
+			case '"':
+				s.str = true
+			case '{', '[':
+				s.depth++
+			case '}', ']':
+				s.depth--
+				if s.depth == 0 {
+					return s.offset + i + 1, true, nil
+				}
+			}
+		}
+	}
+
+	s.offset += len(b)
+	return 0, false, nil
+}
+
+func isNumberByte(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestJSONSplitter_Decode(t *testing.T) {
+	data := ` {"a":"}\"{","b":[1,{"c":null}]}[1,2] "str\\"` + "\n" + `true 12.5e3{}`
+	expect := []string{`{"a":"}\"{","b":[1,{"c":null}]}`, `[1,2]`, `"str\\"`, `true`, `12.5e3`, `{}`}
+
+	// 逐字节写入，每次都尝试解码
+	rb := newWrapped(16, 10)
+	var s JSONSplitter
+	var values []string
+	for i := 0; i < len(data); i++ {
+		_ = rb.WriteByte(data[i])
+		for {
+			b, err := s.Decode(rb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b == nil {
+				break
+			}
+
+			var v interface{}
+			if err = json.Unmarshal(b, &v); err != nil {
+				t.Fatalf("%q: %v", b, err)
+			}
+			raw, _ := json.Marshal(v)
+			values = append(values, string(raw))
+		}
+	}
+
+	if len(values) != len(expect) {
+		t.Fatalf("expect %d values but got %q", len(expect), values)
+	}
+	for i := range expect {
+		var v interface{}
+		_ = json.Unmarshal([]byte(expect[i]), &v)
+		raw, _ := json.Marshal(v)
+		if values[i] != string(raw) {
+			t.Fatalf("expect %s but got %s", raw, values[i])
+		}
+	}
+}
+
+func TestJSONSplitter_Error(t *testing.T) {
+	rb := newWrapped(16, 0)
+	_, _ = rb.WriteString(" }")
+
+	var s JSONSplitter
+	if _, err := s.ValueLength(rb); err != ErrInvalidJSON {
+		t.Fatalf("expect ErrInvalidJSON but got %v", err)
+	}
+
+	rb.RetrieveAll()
+	s = JSONSplitter{MaxValueLength: 8}
+	_, _ = rb.WriteString(`{"key":`)
+	if n, err := s.ValueLength(rb); n != 0 || err != nil {
+		t.Fatalf("expect 0 but got %d, err %v", n, err)
+	}
+	_, _ = rb.WriteString(`"value"}`)
+	if _, err := s.ValueLength(rb); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
+
+func TestJSONSplitter_AtEOF(t *testing.T) {
+	rb := newWrapped(16, 10)
+	_, _ = rb.WriteString(` null 12 -3.5`)
+
+	var s JSONSplitter
+	var values []string
+	for {
+		b, err := s.DecodeAtEOF(rb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b == nil {
+			break
+		}
+		values = append(values, string(b))
+	}
+	if len(values) != 3 || values[0] != " null" || values[1] != " 12" || values[2] != " -3.5" {
+		t.Fatalf("unexpected values %q", values)
+	}
+	if !rb.IsEmpty() {
+		t.Fatal(rb.Length())
+	}
+
+	// 没有 atEOF 时末尾的数字不完整
+	_, _ = rb.WriteString(`42`)
+	if n, err := s.ValueLength(rb); n != 0 || err != nil {
+		t.Fatalf("expect 0 but got %d, err %v", n, err)
+	}
+	if n, err := s.ValueLengthAtEOF(rb); n != 2 || err != nil {
+		t.Fatalf("expect 2 but got %d, err %v", n, err)
+	}
+
+	rb.RetrieveAll()
+	_, _ = rb.WriteString(`{"a":1`)
+	if _, err := s.ValueLengthAtEOF(rb); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expect io.ErrUnexpectedEOF but got %v", err)
+	}
+	rb.RetrieveAll()
+	_, _ = rb.WriteString(`tr`)
+	if _, err := s.ValueLengthAtEOF(rb); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expect io.ErrUnexpectedEOF but got %v", err)
+	}
+}
+
+func TestJSONSplitter_Literal(t *testing.T) {
+	rb := newWrapped(16, 0)
+	_, _ = rb.WriteString(`false`)
+
+	// 字面量不需要后续字符来确定结尾
+	var s JSONSplitter
+	if n, err := s.ValueLength(rb); n != 5 || err != nil {
+		t.Fatalf("expect 5 but got %d, err %v", n, err)
+	}
+
+	rb.RetrieveAll()
+	for _, data := range []string{`trux`, `nul1`, `fa `} {
+		_, _ = rb.WriteString(data)
+		if _, err := s.ValueLength(rb); err != ErrInvalidJSON {
+			t.Fatalf("%s: expect ErrInvalidJSON but got %v", data, err)
+		}
+		rb.RetrieveAll()
+	}
+}
@@ -0,0 +1,138 @@
+// Package codec 提供基于 RingBuffer 的常用拆包器
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+// LengthFieldVarint 长度字段使用 varint（LEB128 无符号）编码
+const LengthFieldVarint = -1
+
+// ErrCorruptedFrame 帧长度字段非法
+var ErrCorruptedFrame = errors.New("codec: corrupted frame")
+
+// TooLongFrameError 帧长度超过 MaxFrameLength
+type TooLongFrameError struct {
+	FrameLength    uint64
+	MaxFrameLength int
+}
+
+func (e *TooLongFrameError) Error() string {
+	return fmt.Sprintf("codec: frame length %d exceeds %d", e.FrameLength, e.MaxFrameLength)
+}
+
+// LengthFieldDecoder 按长度字段拆包，参考 Netty 的 LengthFieldBasedFrameDecoder
+//
+// 帧长度 = LengthFieldOffset + 长度字段字节数 + 长度字段的值 + LengthAdjustment
+type LengthFieldDecoder struct {
+	// LengthFieldOffset 长度字段在帧中的偏移
+	LengthFieldOffset int
+	// LengthFieldLength 长度字段字节数，取值 1、2、4、8 或 LengthFieldVarint
+	LengthFieldLength int
+	// ByteOrder 长度字段字节序，为 nil 时使用大端序
+	ByteOrder binary.ByteOrder
+	// LengthAdjustment 加到长度字段值上的补偿值，例如长度字段包含了头部长度时为负数
+	LengthAdjustment int
+	// InitialBytesToStrip 返回帧时跳过开头的字节数，例如跳过头部只返回 body
+	InitialBytesToStrip int
+	// MaxFrameLength 允许的最大帧长度，为 0 时不限制
+	MaxFrameLength int
+}
+
+// Decode 从 rb 中取出一个完整的帧，并移动读指针
+// 数据不足一帧时返回 nil, nil，不移动读指针
+// 帧过长时返回 *TooLongFrameError，不移动读指针，调用者应当关闭连接
+func (d *LengthFieldDecoder) Decode(rb *ringbuffer.RingBuffer) (frame []byte, err error) {
+	frameLength, err := d.FrameLength(rb)
+	if err != nil || frameLength == 0 {
+		return nil, err
+	}
+
+	if d.InitialBytesToStrip > frameLength {
+		return nil, ErrCorruptedFrame
+	}
+	rb.Retrieve(d.InitialBytesToStrip)
+	frame = make([]byte, frameLength-d.InitialBytesToStrip)
+	_, _ = rb.Read(frame)
+	return
+}
+
+// FrameLength 返回 rb 中第一个帧的总长度，不移动读指针
+// 帧不完整时返回 0
+func (d *LengthFieldDecoder) FrameLength(rb *ringbuffer.RingBuffer) (int, error) {
+	length, fieldLength, err := d.lengthField(rb)
+	if err != nil || fieldLength == 0 {
+		return 0, err
+	}
+
+	headerLength := d.LengthFieldOffset + fieldLength
+	frameLength := int64(headerLength) + int64(d.LengthAdjustment)
+	if length > uint64(maxInt) || frameLength+int64(length) > int64(maxInt) {
+		return 0, &TooLongFrameError{FrameLength: length, MaxFrameLength: d.MaxFrameLength}
+	}
+	frameLength += int64(length)
+	if frameLength < int64(headerLength) {
+		return 0, ErrCorruptedFrame
+	}
+	if d.MaxFrameLength > 0 && frameLength > int64(d.MaxFrameLength) {
+		return 0, &TooLongFrameError{FrameLength: uint64(frameLength), MaxFrameLength: d.MaxFrameLength}
+	}
+
+	if int64(rb.Length()) < frameLength {
+		return 0, nil
+	}
+	return int(frameLength), nil
+}
+
+// lengthField 读取长度字段的值及其字节数，数据不足时 fieldLength 为 0
+func (d *LengthFieldDecoder) lengthField(rb *ringbuffer.RingBuffer) (length uint64, fieldLength int, err error) {
+	order := d.ByteOrder
+	if order == nil {
+		order = binary.BigEndian
+	}
+
+	if d.LengthFieldLength == LengthFieldVarint {
+		var tmp [binary.MaxVarintLen64]byte
+		first, end := rb.PeekAt(d.LengthFieldOffset, len(tmp))
+		n := copy(tmp[:], first)
+		n += copy(tmp[n:], end)
+
+		length, fieldLength = binary.Uvarint(tmp[:n])
+		if fieldLength < 0 {
+			return 0, 0, ErrCorruptedFrame
+		}
+		return
+	}
+
+	switch d.LengthFieldLength {
+	case 1, 2, 4, 8:
+	default:
+		return 0, 0, fmt.Errorf("codec: unsupported length field length %d", d.LengthFieldLength)
+	}
+	if rb.Length() < d.LengthFieldOffset+d.LengthFieldLength {
+		return 0, 0, nil
+	}
+
+	var tmp [8]byte
+	first, end := rb.PeekAt(d.LengthFieldOffset, d.LengthFieldLength)
+	n := copy(tmp[:], first)
+	copy(tmp[n:], end)
+
+	switch d.LengthFieldLength {
+	case 1:
+		length = uint64(tmp[0])
+	case 2:
+		length = uint64(order.Uint16(tmp[:]))
+	case 4:
+		length = uint64(order.Uint32(tmp[:]))
+	case 8:
+		length = order.Uint64(tmp[:])
+	}
+	return length, d.LengthFieldLength, nil
+}
+
+const maxInt = int(^uint(0) >> 1)
@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+// newWrapped 返回一个写指针已经移动 skip 字节的空 RingBuffer，后续写入的数据会跨越环尾
+func newWrapped(size, skip int) *ringbuffer.RingBuffer {
+	rb := ringbuffer.New(size)
+	_, _ = rb.Write(make([]byte, skip))
+	_, _ = rb.Read(make([]byte, skip))
+	return rb
+}
+
+func TestLengthFieldDecoder_Decode(t *testing.T) {
+	d := &LengthFieldDecoder{
+		LengthFieldOffset:   2,
+		LengthFieldLength:   4,
+		InitialBytesToStrip: 6,
+	}
+	rb := newWrapped(16, 12)
+
+	frame, err := d.Decode(rb)
+	if frame != nil || err != nil {
+		t.Fatalf("expect nil frame but got %q, err %v", frame, err)
+	}
+
+	_, _ = rb.Write([]byte{0xca, 0xfe, 0, 0, 0, 5, 'h', 'e'})
+	frame, err = d.Decode(rb)
+	if frame != nil || err != nil {
+		t.Fatalf("expect nil frame but got %q, err %v", frame, err)
+	}
+	if rb.Length() != 8 {
+		t.Fatalf("expect len 8 bytes but got %d", rb.Length())
+	}
+
+	_, _ = rb.Write([]byte("llo"))
+	frame, err = d.Decode(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(frame, []byte("hello")) {
+		t.Fatalf("expect hello but got %q", frame)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect empty but got len %d", rb.Length())
+	}
+}
+
+func TestLengthFieldDecoder_Adjustment(t *testing.T) {
+	// 长度字段包含了自身的长度
+	d := &LengthFieldDecoder{
+		LengthFieldLength: 2,
+		ByteOrder:         binary.LittleEndian,
+		LengthAdjustment:  -2,
+	}
+	rb := newWrapped(8, 7)
+	_, _ = rb.Write([]byte{5, 0, 'a', 'b', 'c', 'x'})
+
+	frame, err := d.Decode(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(frame, []byte{5, 0, 'a', 'b', 'c'}) {
+		t.Fatalf("unexpected frame %q", frame)
+	}
+	if rb.Length() != 1 {
+		t.Fatalf("expect len 1 bytes but got %d", rb.Length())
+	}
+
+	rb.RetrieveAll()
+	_, _ = rb.Write([]byte{1, 0})
+	if _, err = d.Decode(rb); err != ErrCorruptedFrame {
+		t.Fatalf("expect ErrCorruptedFrame but got %v", err)
+	}
+}
+
+func TestLengthFieldDecoder_Varint(t *testing.T) {
+	d := &LengthFieldDecoder{
+		LengthFieldLength:   LengthFieldVarint,
+		InitialBytesToStrip: 2,
+		MaxFrameLength:      512,
+	}
+	rb := newWrapped(512, 511)
+
+	payload := bytes.Repeat([]byte("a"), 300)
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(len(payload)))
+	if n != 2 {
+		t.Fatalf("expect 2 bytes varint but got %d", n)
+	}
+
+	_, _ = rb.Write(header[:1])
+	frame, err := d.Decode(rb)
+	if frame != nil || err != nil {
+		t.Fatalf("expect nil frame but got %q, err %v", frame, err)
+	}
+
+	_, _ = rb.Write(header[1:n])
+	_, _ = rb.Write(payload)
+	frame, err = d.Decode(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(frame, payload) {
+		t.Fatalf("unexpected frame len %d", len(frame))
+	}
+}
+
+func TestLengthFieldDecoder_TooLong(t *testing.T) {
+	d := &LengthFieldDecoder{
+		LengthFieldLength: 4,
+		MaxFrameLength:    1024,
+	}
+	rb := ringbuffer.New(16)
+	_, _ = rb.Write([]byte{0, 0, 4, 0})
+
+	_, err := d.Decode(rb)
+	e, ok := err.(*TooLongFrameError)
+	if !ok {
+		t.Fatalf("expect *TooLongFrameError but got %v", err)
+	}
+	if e.FrameLength != 1028 || e.MaxFrameLength != 1024 {
+		t.Fatalf("unexpected error %v", e)
+	}
+	if rb.Length() != 4 {
+		t.Fatalf("expect len 4 bytes but got %d", rb.Length())
+	}
+
+	d.LengthFieldLength = 3
+	if _, err = d.Decode(rb); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
@@ -0,0 +1,180 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+// TLV 一条 type-length-value 记录
+// Value 可能跨越环尾，分为 First 和 End 两段，直接引用 RingBuffer 的内部缓冲区，在下一次写入前有效
+type TLV struct {
+	Type  uint64
+	First []byte
+	End   []byte
+}
+
+// Length value 的长度
+func (t TLV) Length() int {
+	return len(t.First) + len(t.End)
+}
+
+// Bytes 拷贝出 value
+func (t TLV) Bytes() []byte {
+	b := make([]byte, t.Length())
+	n := copy(b, t.First)
+	copy(b[n:], t.End)
+	return b
+}
+
+// TLVReader 从 RingBuffer 中读取 TLV 记录，支持嵌套的 TLV 容器
+type TLVReader struct {
+	// TypeLength type 字段字节数，取值 1、2、4、8
+	TypeLength int
+	// LengthLength length 字段字节数，取值 1、2、4、8
+	LengthLength int
+	// ByteOrder 字节序，为 nil 时使用大端序
+	ByteOrder binary.ByteOrder
+	// MaxValueLength value 的最大长度，为 0 时不限制
+	MaxValueLength int
+}
+
+// segmentSource 可以按偏移查看的分段数据，RingBuffer 和嵌套容器的 value 都满足
+type segmentSource interface {
+	Length() int
+	PeekAt(offset, n int) (first []byte, end []byte)
+}
+
+// Next 从 rb 中读取一条完整的 TLV 记录并移动读指针，不拷贝 value
+// 记录不完整时 ok 为 false，不移动读指针
+func (r *TLVReader) Next(rb *ringbuffer.RingBuffer) (t TLV, ok bool, err error) {
+	t, n, err := r.peek(rb, 0)
+	if err != nil || n == 0 {
+		return t, false, err
+	}
+
+	rb.Retrieve(n)
+	return t, true, nil
+}
+
+// Children 返回遍历容器 t 中嵌套的 TLV 记录的迭代器
+func (r *TLVReader) Children(t TLV) *TLVIterator {
+	return &TLVIterator{reader: r, src: segments{first: t.First, end: t.End}}
+}
+
+// peek 查看 src 中偏移 offset 处的记录，返回记录总长度，记录不完整时为 0
+func (r *TLVReader) peek(src segmentSource, offset int) (t TLV, n int, err error) {
+	if !validWidth(r.TypeLength) || !validWidth(r.LengthLength) {
+		return t, 0, fmt.Errorf("codec: unsupported TLV field length %d/%d", r.TypeLength, r.LengthLength)
+	}
+
+	headerLength := r.TypeLength + r.LengthLength
+	if src.Length()-offset < headerLength {
+		return t, 0, nil
+	}
+
+	t.Type = r.uint(src, offset, r.TypeLength)
+	length := r.uint(src, offset+r.TypeLength, r.LengthLength)
+	if length > uint64(maxInt-headerLength) || (r.MaxValueLength > 0 && length > uint64(r.MaxValueLength)) {
+		return t, 0, &TooLongFrameError{FrameLength: length, MaxFrameLength: r.MaxValueLength}
+	}
+
+	n = headerLength + int(length)
+	if src.Length()-offset < n {
+		return t, 0, nil
+	}
+	t.First, t.End = src.PeekAt(offset+headerLength, int(length))
+	return t, n, nil
+}
+
+func (r *TLVReader) uint(src segmentSource, offset, width int) uint64 {
+	order := r.ByteOrder
+	if order == nil {
+		order = binary.BigEndian
+	}
+
+	var tmp [8]byte
+	first, end := src.PeekAt(offset, width)
+	n := copy(tmp[:], first)
+	copy(tmp[n:], end)
+
+	switch width {
+	case 1:
+		return uint64(tmp[0])
+	case 2:
+		return uint64(order.Uint16(tmp[:]))
+	case 4:
+		return uint64(order.Uint32(tmp[:]))
+	default:
+		return order.Uint64(tmp[:])
+	}
+}
+
+func validWidth(width int) bool {
+	switch width {
+	case 1, 2, 4, 8:
+		return true
+	}
+	return false
+}
+
+// TLVIterator 遍历嵌套容器中的 TLV 记录
+type TLVIterator struct {
+	reader *TLVReader
+	src    segments
+	offset int
+	err    error
+}
+
+// Next 返回下一条记录，遍历结束或者出错时 ok 为 false
+func (it *TLVIterator) Next() (t TLV, ok bool) {
+	if it.err != nil || it.offset >= it.src.Length() {
+		return t, false
+	}
+
+	t, n, err := it.reader.peek(it.src, it.offset)
+	if err == nil && n == 0 {
+		// 容器本身是完整的，其中的记录不完整说明数据有误
+		err = ErrCorruptedFrame
+	}
+	if err != nil {
+		it.err = err
+		return t, false
+	}
+
+	it.offset += n
+	return t, true
+}
+
+// Err 返回遍历过程中遇到的错误
+func (it *TLVIterator) Err() error {
+	return it.err
+}
+
+// segments 由两段数据组成的只读视图
+type segments struct {
+	first, end []byte
+}
+
+func (s segments) Length() int {
+	return len(s.first) + len(s.end)
+}
+
+func (s segments) PeekAt(offset, n int) (first []byte, end []byte) {
+	if offset < 0 || n <= 0 || offset >= s.Length() {
+		return
+	}
+	if n > s.Length()-offset {
+		n = s.Length() - offset
+	}
+
+	if offset >= len(s.first) {
+		offset -= len(s.first)
+		return s.end[offset : offset+n], nil
+	}
+	if offset+n <= len(s.first) {
+		return s.first[offset : offset+n], nil
+	}
+	return s.first[offset:], s.end[:n-(len(s.first)-offset)]
+}
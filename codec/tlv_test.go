@@ -0,0 +1,78 @@
+package codec
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestTLVReader_Next(t *testing.T) {
+	r := &TLVReader{TypeLength: 1, LengthLength: 2, ByteOrder: binary.LittleEndian, MaxValueLength: 64}
+	rb := newWrapped(32, 20)
+
+	_, _ = rb.Write([]byte{0x01, 3, 0, 'a', 'b', 'c', 0x02, 5, 0, 'h'})
+	tlv, ok, err := r.Next(rb)
+	if err != nil || !ok {
+		t.Fatalf("expect ok but got %v, err %v", ok, err)
+	}
+	if tlv.Type != 1 || string(tlv.Bytes()) != "abc" {
+		t.Fatalf("unexpected tlv %d %q", tlv.Type, tlv.Bytes())
+	}
+
+	_, ok, err = r.Next(rb)
+	if ok || err != nil {
+		t.Fatalf("expect partial record but got %v, err %v", ok, err)
+	}
+	if rb.Length() != 4 {
+		t.Fatalf("expect len 4 bytes but got %d", rb.Length())
+	}
+
+	_, _ = rb.Write([]byte("ello"))
+	tlv, ok, err = r.Next(rb)
+	if err != nil || !ok {
+		t.Fatalf("expect ok but got %v, err %v", ok, err)
+	}
+	if tlv.Type != 2 || tlv.Length() != 5 || len(tlv.End) == 0 || string(tlv.Bytes()) != "hello" {
+		t.Fatalf("unexpected tlv %d %q %q", tlv.Type, tlv.First, tlv.End)
+	}
+
+	_, _ = rb.Write([]byte{0x03, 0xff, 0})
+	if _, _, err = r.Next(rb); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
+
+func TestTLVReader_Children(t *testing.T) {
+	r := &TLVReader{TypeLength: 2, LengthLength: 1}
+	rb := newWrapped(32, 24)
+
+	// 容器 0x0100 包含两条记录
+	_, _ = rb.Write([]byte{0x01, 0x00, 9, 0x00, 0x01, 2, 'h', 'i', 0x00, 0x02, 1, '!'})
+	container, ok, err := r.Next(rb)
+	if err != nil || !ok {
+		t.Fatalf("expect ok but got %v, err %v", ok, err)
+	}
+	if container.Type != 0x0100 || len(container.End) == 0 {
+		t.Fatalf("unexpected container %d %q %q", container.Type, container.First, container.End)
+	}
+
+	it := r.Children(container)
+	var values []string
+	for {
+		child, ok := it.Next()
+		if !ok {
+			break
+		}
+		values = append(values, string(child.Bytes()))
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if len(values) != 2 || values[0] != "hi" || values[1] != "!" {
+		t.Fatalf("unexpected children %q", values)
+	}
+
+	it = r.Children(TLV{First: []byte{0x00, 0x01, 5, 'a'}})
+	if _, ok = it.Next(); ok || it.Err() != ErrCorruptedFrame {
+		t.Fatalf("expect ErrCorruptedFrame but got %v", it.Err())
+	}
+}
@@ -0,0 +1,35 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// LeakHandler 处理从 Pool 中 Get 之后没有 Put 就被 GC 回收的 RingBuffer，getStack 为 Get 时的调用栈
+// 仅在使用 ringbuffer_debug 构建标签编译时生效
+type LeakHandler func(getStack []byte)
+
+var (
+	leakHandlerMu sync.RWMutex
+	leakHandler   LeakHandler = func(getStack []byte) {
+		fmt.Fprintf(os.Stderr, "ringbuffer: buffer obtained from pool was never put back, Get called at:\n%s\n", getStack)
+	}
+)
+
+// SetLeakHandler 设置泄漏处理函数，默认打印到标准错误输出
+// 仅在使用 ringbuffer_debug 构建标签编译时生效
+func SetLeakHandler(h LeakHandler) {
+	leakHandlerMu.Lock()
+	leakHandler = h
+	leakHandlerMu.Unlock()
+}
+
+func reportLeak(getStack []byte) {
+	leakHandlerMu.RLock()
+	h := leakHandler
+	leakHandlerMu.RUnlock()
+	if h != nil {
+		h(getStack)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !ringbuffer_debug
+// +build !ringbuffer_debug
+
+package ringbuffer
+
+// DebugEnabled 是否使用 ringbuffer_debug 构建标签编译
+const DebugEnabled = false
+
+// debugState 仅在使用 ringbuffer_debug 构建标签时记录调试信息，否则不占用空间
+type debugState struct{}
+
+func (r *RingBuffer) checkUse() {}
+
+func debugTrackGet(r *RingBuffer) {}
+
+func debugTrackPut(r *RingBuffer) (recycle bool) { return true }
+
+func debugTrackRelease(r *RingBuffer) {}
+
+func debugFinalize(r *RingBuffer) {}
@@ -0,0 +1,61 @@
+//go:build ringbuffer_debug
+// +build ringbuffer_debug
+
+package ringbuffer
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// DebugEnabled 是否使用 ringbuffer_debug 构建标签编译
+//
+// 使用 ringbuffer_debug 构建标签时：
+//  1. Put 之后的 RingBuffer 不会再被复用，底层数组被填充为 poisonByte，之后调用它的任何方法都会 panic 并打印 Put 时的调用栈
+//  2. Get 之后没有 Put 就被 GC 回收的 RingBuffer 会通过 LeakHandler 报告
+//  3. 引用计数归零之后再调用 Release、Retain 等方法会 panic 并打印归零时 Put 或 Release 的调用栈
+const DebugEnabled = true
+
+// poisonByte Put 之后填充底层数组的字节
+const poisonByte = 0xdd
+
+// debugState 记录在 RingBuffer 上的调试信息
+type debugState struct {
+	getStack []byte // 最近一次 Get 时的调用栈
+	putStack []byte // Put 或者引用计数归零时 Release 的调用栈，不为 nil 表示已经放回
+}
+
+func (r *RingBuffer) checkUse() {
+	if r.debug.putStack != nil {
+		panic(fmt.Sprintf("ringbuffer: use of buffer after it was put back to the pool or released, Put/Release called at:\n%s", r.debug.putStack))
+	}
+}
+
+func debugTrackGet(r *RingBuffer) {
+	r.debug.getStack = stack()
+	r.debug.putStack = nil
+	r.ensureFinalizer()
+}
+
+func debugTrackPut(r *RingBuffer) (recycle bool) {
+	r.debug.putStack = stack()
+	for i := range r.buf {
+		r.buf[i] = poisonByte
+	}
+	return false
+}
+
+func debugTrackRelease(r *RingBuffer) {
+	r.debug.putStack = stack()
+}
+
+func debugFinalize(r *RingBuffer) {
+	if r.debug.putStack == nil && r.debug.getStack != nil {
+		reportLeak(r.debug.getStack)
+	}
+}
+
+func stack() []byte {
+	buf := make([]byte, 4096)
+	return buf[:runtime.Stack(buf, false)]
+}
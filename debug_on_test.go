@@ -0,0 +1,83 @@
+//go:build ringbuffer_debug
+// +build ringbuffer_debug
+
+package ringbuffer
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebug_UseAfterPut(t *testing.T) {
+	p := NewPool(PoolOptions{})
+	b := p.GetWithCapacity(64)
+	_, _ = b.Write([]byte("abcd"))
+	first, _ := b.PeekAll()
+	p.Put(b)
+
+	if first[0] != poisonByte {
+		t.Fatalf("expect poisoned buffer but got %x", first[0])
+	}
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "TestDebug_UseAfterPut") {
+			t.Fatalf("expect panic with Put stack but got %q", msg)
+		}
+	}()
+	b.Length()
+}
+
+func TestDebug_Leak(t *testing.T) {
+	leaked := make(chan []byte, 1)
+	SetLeakHandler(func(getStack []byte) {
+		select {
+		case leaked <- getStack:
+		default:
+		}
+	})
+	defer SetLeakHandler(nil)
+
+	func() {
+		p := NewPool(PoolOptions{})
+		_ = p.Get()
+	}()
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case s := <-leaked:
+			if !strings.Contains(string(s), "TestDebug_Leak") {
+				t.Fatalf("expect Get stack but got %s", s)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("expect leak report")
+}
+
+func TestDebug_PutWithoutGet(t *testing.T) {
+	p := NewPool(PoolOptions{})
+	for i := 0; i < 100; i++ {
+		p.Put(New(64))
+		runtime.GC()
+		// 地址被复用时不能误报 use after put
+		New(64).Length()
+	}
+}
+
+func TestDebug_DoubleRelease(t *testing.T) {
+	b := New(8)
+	b.Release()
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "TestDebug_DoubleRelease") {
+			t.Fatalf("expect panic with Release stack but got %q", msg)
+		}
+	}()
+	b.Release()
+}
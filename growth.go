@@ -0,0 +1,128 @@
+package ringbuffer
+
+// GrowthStrategy 扩容策略
+//
+// Grow 根据当前容量 size 和需要的最小容量 need 返回新容量，返回值小于 need 时按 need 处理
+type GrowthStrategy interface {
+	Grow(size, need int) int
+}
+
+// GrowthFunc 将普通函数适配为 GrowthStrategy
+type GrowthFunc func(size, need int) int
+
+// Grow 调用 f(size, need)
+func (f GrowthFunc) Grow(size, need int) int {
+	return f(size, need)
+}
+
+// AppendGrowth 默认的扩容策略，参考 golang 切片 append 策略：
+// 小于 1024 时翻倍，之后每次增加 25%，期望容量大于两倍时直接使用期望容量
+type AppendGrowth struct{}
+
+// Grow 实现 GrowthStrategy
+func (AppendGrowth) Grow(size, need int) int {
+	newcap := size
+	doublecap := newcap + newcap
+	if need > doublecap {
+		newcap = need
+	} else {
+		if size < 1024 {
+			newcap = doublecap
+		} else {
+			for 0 < newcap && newcap < need {
+				newcap += newcap / 4
+			}
+			if newcap <= 0 {
+				newcap = need
+			}
+		}
+	}
+	return newcap
+}
+
+// DoublingGrowth 容量一直翻倍，直到不小于期望容量
+type DoublingGrowth struct{}
+
+// Grow 实现 GrowthStrategy
+func (DoublingGrowth) Grow(size, need int) int {
+	newcap := size
+	if newcap <= 0 {
+		return need
+	}
+	for newcap < need {
+		newcap += newcap
+		if newcap <= 0 {
+			return need
+		}
+	}
+	return newcap
+}
+
+// FixedGrowth 每次增加固定大小，适合容量变化不大的小缓冲区
+type FixedGrowth int
+
+// Grow 实现 GrowthStrategy，增量不大于 0 时直接使用期望容量
+func (g FixedGrowth) Grow(size, need int) int {
+	step := int(g)
+	if step <= 0 || size < 0 {
+		return need
+	}
+	return size + (need-size+step-1)/step*step
+}
+
+// PowerOfTwoGrowth 向上取整到 2 的幂
+type PowerOfTwoGrowth struct{}
+
+// Grow 实现 GrowthStrategy
+func (PowerOfTwoGrowth) Grow(size, need int) int {
+	if need <= 1 || need > int(^uint(0)>>2) {
+		return need
+	}
+	return 1 << uint(bitSize(need))
+}
+
+// SizeClassGrowth 向上对齐到 Pool 的 size class，Put 回 Pool 时能落在刚好用满的档位
+type SizeClassGrowth struct {
+	pool *Pool
+}
+
+// NewSizeClassGrowth 创建对齐到 p 的 size class 的扩容策略，p 为 nil 时使用默认的 size class
+func NewSizeClassGrowth(p *Pool) *SizeClassGrowth {
+	return &SizeClassGrowth{pool: p}
+}
+
+// Grow 实现 GrowthStrategy，超过最大 size class 时直接使用期望容量
+func (g *SizeClassGrowth) Grow(size, need int) int {
+	minBits, stepCount := minBitSize, steps
+	if g.pool != nil {
+		_, _, minBits, stepCount = g.pool.config()
+	}
+	if need <= 1<<uint(minBits) {
+		return 1 << uint(minBits)
+	}
+	if need > 1<<uint(minBits+stepCount-1) {
+		return need
+	}
+	return 1 << uint(minBits+index(need, minBits, stepCount))
+}
+
+// SetGrowthStrategy 设置扩容策略，为 nil 时恢复默认的 AppendGrowth
+func (r *RingBuffer) SetGrowthStrategy(g GrowthStrategy) {
+	r.checkUse()
+	r.growth = g
+}
+
+// Grow 保证之后至少还能写入 n 个字节而不需要扩容，类似 bytes.Buffer.Grow
+// n 为负数时 panic，关联了 Budget 且超出预算时返回错误
+func (r *RingBuffer) Grow(n int) error {
+	r.checkUse()
+	if n < 0 {
+		panic("ringbuffer: negative count")
+	}
+	// 预留的空间之后会被写入，取消待执行的自动缩容
+	r.cancelShrink()
+	if free := r.free(); n > free {
+		return r.makeSpace(n - free)
+	}
+	return nil
+}
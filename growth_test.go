@@ -0,0 +1,96 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGrowthStrategy(t *testing.T) {
+	pool := NewPool(PoolOptions{MinSize: 128, MaxSize: 1024})
+	tests := []struct {
+		name       string
+		g          GrowthStrategy
+		size, need int
+		expect     int
+	}{
+		{"append double", AppendGrowth{}, 100, 101, 200},
+		{"append large", AppendGrowth{}, 100, 300, 300},
+		{"append 25%", AppendGrowth{}, 2048, 2049, 2560},
+		{"doubling", DoublingGrowth{}, 100, 350, 400},
+		{"doubling zero", DoublingGrowth{}, 0, 10, 10},
+		{"fixed", FixedGrowth(64), 100, 101, 164},
+		{"fixed multi", FixedGrowth(64), 100, 300, 356},
+		{"fixed zero", FixedGrowth(0), 100, 101, 101},
+		{"power of two", PowerOfTwoGrowth{}, 100, 101, 128},
+		{"power of two exact", PowerOfTwoGrowth{}, 100, 256, 256},
+		{"size class min", NewSizeClassGrowth(nil), 10, 11, 64},
+		{"size class", NewSizeClassGrowth(nil), 100, 200, 256},
+		{"pool size class min", NewSizeClassGrowth(pool), 10, 11, 128},
+		{"pool size class max", NewSizeClassGrowth(pool), 1024, 1025, 1025},
+		{"func", GrowthFunc(func(size, need int) int { return need + 1 }), 10, 11, 12},
+	}
+	for _, tt := range tests {
+		if got := tt.g.Grow(tt.size, tt.need); got != tt.expect {
+			t.Fatalf("%s: expect %d but got %d", tt.name, tt.expect, got)
+		}
+	}
+}
+
+func TestRingBuffer_SetGrowthStrategy(t *testing.T) {
+	rb := New(4)
+	rb.SetGrowthStrategy(FixedGrowth(10))
+	_, _ = rb.WriteString("abcde")
+	if rb.Capacity() != 14 {
+		t.Fatalf("expect capacity 14 but got %d", rb.Capacity())
+	}
+
+	// 返回值小于期望容量时按期望容量处理
+	rb.SetGrowthStrategy(GrowthFunc(func(size, need int) int { return 0 }))
+	_, _ = rb.Write(make([]byte, 20))
+	if rb.Capacity() != 25 {
+		t.Fatalf("expect capacity 25 but got %d", rb.Capacity())
+	}
+
+	rb.SetGrowthStrategy(nil)
+	_ = rb.WriteByte('x')
+	if rb.Capacity() != 50 {
+		t.Fatalf("expect capacity 50 but got %d", rb.Capacity())
+	}
+}
+
+func TestRingBuffer_Grow(t *testing.T) {
+	rb := New(8)
+	_, _ = rb.WriteString("abcdef")
+	_, _ = rb.Read(make([]byte, 4))
+	_, _ = rb.WriteString("ghij")
+
+	if err := rb.Grow(2); err != nil || rb.Capacity() != 8 {
+		t.Fatalf("unexpected err %v, cap %d", err, rb.Capacity())
+	}
+	rb.SetGrowthStrategy(PowerOfTwoGrowth{})
+	if err := rb.Grow(100); err != nil {
+		t.Fatal(err)
+	}
+	if rb.Capacity() != 128 || rb.Length() != 6 {
+		t.Fatalf("unexpected cap %d, len %d", rb.Capacity(), rb.Length())
+	}
+	if !bytes.Equal(rb.Bytes(), []byte("efghij")) {
+		t.Fatalf("expect efghij but got %s", rb.Bytes())
+	}
+
+	budget := NewBudget(200, false)
+	rb, _ = NewWithBudget(100, budget)
+	if err := rb.Grow(250); err != ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+	if rb.Capacity() != 100 {
+		t.Fatalf("expect capacity 100 but got %d", rb.Capacity())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expect panic")
+		}
+	}()
+	_ = rb.Grow(-1)
+}
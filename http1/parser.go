@@ -0,0 +1,263 @@
+// Package http1 从 RingBuffer 中增量解析 HTTP/1.x 请求头
+package http1
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+var (
+	// ErrNeedMore 数据不完整，需要等待更多数据
+	ErrNeedMore = errors.New("http1: need more data")
+	// ErrMalformed 请求格式错误
+	ErrMalformed = errors.New("http1: malformed request")
+	// ErrHeaderTooLarge 请求头超过 MaxHeaderSize
+	ErrHeaderTooLarge = errors.New("http1: request header too large")
+)
+
+// DefaultMaxHeaderSize 默认的请求头最大长度
+const DefaultMaxHeaderSize = 64 << 10
+
+// maxChunkSizeLine chunk-size 行（包含 chunk-ext）的最大长度
+const maxChunkSizeLine = 4096
+
+// Header 请求头中的一个字段
+type Header struct {
+	Key   string
+	Value string
+}
+
+// Request 解析出的请求头
+type Request struct {
+	Method string
+	URI    string
+	Proto  string // "HTTP/1.1"
+	Major  int
+	Minor  int
+
+	Headers []Header
+
+	// ContentLength body 长度，未设置或者使用 chunked 编码时为 -1
+	ContentLength int64
+	// Chunked 是否使用 chunked 编码，为 true 时 body 需要使用 ParseChunkSize 逐块读取
+	// Transfer-Encoding 的最后一个 coding 不是 chunked，或者同时带有 Content-Length 时，Parse 返回 ErrMalformed
+	Chunked bool
+}
+
+// Header 返回 key 对应的第一个值，key 不区分大小写
+func (req *Request) Header(key string) string {
+	for _, h := range req.Headers {
+		if strings.EqualFold(h.Key, key) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// KeepAlive 请求处理完后是否保持连接
+func (req *Request) KeepAlive() bool {
+	conn := req.Header("Connection")
+	if req.Major == 1 && req.Minor == 0 {
+		return strings.EqualFold(conn, "keep-alive")
+	}
+	return !strings.EqualFold(conn, "close")
+}
+
+// Parser 增量解析请求头，会记录已经扫描过的位置，避免数据分多次到达时重复扫描
+//
+// Parser 带有状态，每个连接需要使用单独的实例
+type Parser struct {
+	// MaxHeaderSize 请求头最大长度，为 0 时使用 DefaultMaxHeaderSize
+	MaxHeaderSize int
+
+	checked int
+}
+
+// Parse 解析 rb 中的请求头
+// 请求头不完整时返回 ErrNeedMore，不移动读指针；解析成功时仅移动读指针到 body 的开头
+func (p *Parser) Parse(rb *ringbuffer.RingBuffer) (*Request, error) {
+	// 忽略请求行之前的空行，例如上一个请求 body 之后多余的 "\r\n"
+	if p.checked == 0 {
+		for !rb.IsEmpty() {
+			c := rb.PeekUint8()
+			if c == '\n' || (c == '\r' && rb.PeekUint8At(1) == '\n') {
+				rb.Retrieve(1)
+				continue
+			}
+			break
+		}
+	}
+
+	headLength, err := p.headLength(rb)
+	if err != nil {
+		return nil, err
+	}
+	p.checked = 0
+
+	first, end := rb.Peek(headLength)
+	head := make([]byte, 0, headLength)
+	head = append(head, first...)
+	head = append(head, end...)
+
+	req, err := parseHead(string(head))
+	if err != nil {
+		return nil, err
+	}
+	rb.Retrieve(headLength)
+	return req, nil
+}
+
+// headLength 返回包含结尾空行在内的请求头长度
+func (p *Parser) headLength(rb *ringbuffer.RingBuffer) (int, error) {
+	maxHeaderSize := p.MaxHeaderSize
+	if maxHeaderSize <= 0 {
+		maxHeaderSize = DefaultMaxHeaderSize
+	}
+
+	pos := p.checked
+	length := rb.Length()
+	if pos > length {
+		pos = 0
+	}
+	for {
+		i := indexByte(rb, pos, '\n')
+		if i < 0 {
+			if length > maxHeaderSize {
+				return 0, ErrHeaderTooLarge
+			}
+			p.checked = pos
+			return 0, ErrNeedMore
+		}
+		if i+1 > maxHeaderSize {
+			return 0, ErrHeaderTooLarge
+		}
+
+		// 空行表示请求头结束
+		if i == pos || (i == pos+1 && rb.PeekUint8At(pos) == '\r') {
+			return i + 1, nil
+		}
+		pos = i + 1
+	}
+}
+
+// ParseChunkSize 解析 chunked 编码中的 chunk-size 行并移动读指针，之后的 size 字节为 chunk 数据，
+// 数据之后还有 "\r\n"；size 为 0 表示最后一个 chunk，之后是 trailer 和空行
+// 数据不完整时返回 ErrNeedMore，不移动读指针
+func ParseChunkSize(rb *ringbuffer.RingBuffer) (size int64, err error) {
+	i := indexByte(rb, 0, '\n')
+	if i < 0 {
+		if rb.Length() > maxChunkSizeLine {
+			return 0, ErrMalformed
+		}
+		return 0, ErrNeedMore
+	}
+
+	first, end := rb.Peek(i)
+	line := strings.TrimRight(string(first)+string(end), "\r")
+	if j := strings.IndexByte(line, ';'); j >= 0 {
+		line = line[:j]
+	}
+	size, err = strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+	if err != nil || size < 0 {
+		return 0, ErrMalformed
+	}
+
+	rb.Retrieve(i + 1)
+	return size, nil
+}
+
+func parseHead(head string) (*Request, error) {
+	lines := strings.Split(strings.TrimRight(head, "\r\n"), "\n")
+
+	req := &Request{ContentLength: -1}
+	if err := parseRequestLine(req, strings.TrimRight(lines[0], "\r")); err != nil {
+		return nil, err
+	}
+
+	var transferEncoding []string
+	req.Headers = make([]Header, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, "\r")
+		i := strings.IndexByte(line, ':')
+		if i <= 0 || strings.ContainsAny(line[:i], " \t") {
+			return nil, ErrMalformed
+		}
+		key := line[:i]
+		value := strings.Trim(line[i+1:], " \t")
+		req.Headers = append(req.Headers, Header{Key: key, Value: value})
+
+		switch {
+		case strings.EqualFold(key, "Content-Length"):
+			n, ok := parseContentLength(value)
+			if !ok {
+				return nil, ErrMalformed
+			}
+			if req.ContentLength >= 0 && req.ContentLength != n {
+				return nil, ErrMalformed
+			}
+			req.ContentLength = n
+		case strings.EqualFold(key, "Transfer-Encoding"):
+			transferEncoding = append(transferEncoding, strings.Split(value, ",")...)
+		}
+	}
+
+	if transferEncoding != nil {
+		// RFC 7230 3.3.3：最后一个 coding 不是 chunked 时无法确定请求 body 的长度；
+		// 同时带有 Content-Length 时前后端可能按不同的方式确定 body 长度，导致请求走私，两者都视为格式错误
+		if !strings.EqualFold(strings.TrimSpace(transferEncoding[len(transferEncoding)-1]), "chunked") || req.ContentLength >= 0 {
+			return nil, ErrMalformed
+		}
+		req.Chunked = true
+	}
+	return req, nil
+}
+
+// parseContentLength 解析 1*DIGIT 形式的 Content-Length，不接受符号、空白等其他字符
+func parseContentLength(value string) (int64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return 0, false
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	return n, err == nil
+}
+
+func parseRequestLine(req *Request, line string) error {
+	i := strings.IndexByte(line, ' ')
+	j := strings.LastIndexByte(line, ' ')
+	if i <= 0 || j <= i+1 {
+		return ErrMalformed
+	}
+	req.Method, req.URI, req.Proto = line[:i], line[i+1:j], line[j+1:]
+
+	if !strings.HasPrefix(req.Proto, "HTTP/") || len(req.Proto) != len("HTTP/1.1") || req.Proto[6] != '.' {
+		return ErrMalformed
+	}
+	major, minor := req.Proto[5], req.Proto[7]
+	if major < '0' || major > '9' || minor < '0' || minor > '9' {
+		return ErrMalformed
+	}
+	req.Major, req.Minor = int(major-'0'), int(minor-'0')
+	return nil
+}
+
+// indexByte 从偏移 offset 处开始查找 c，返回相对读指针的偏移
+func indexByte(rb *ringbuffer.RingBuffer, offset int, c byte) int {
+	first, end := rb.PeekAt(offset, rb.Length()-offset)
+	if i := bytes.IndexByte(first, c); i >= 0 {
+		return offset + i
+	}
+	if i := bytes.IndexByte(end, c); i >= 0 {
+		return offset + len(first) + i
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package http1
+
+import (
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+func TestParser_Parse(t *testing.T) {
+	rb := ringbuffer.New(64)
+	_, _ = rb.Write(make([]byte, 40))
+	_, _ = rb.Read(make([]byte, 40))
+
+	var p Parser
+	chunks := []string{
+		"\r\nGET /index.html HTTP/1.1\r\nHo",
+		"st: example.com\r\nContent-Length:  5 \r\n",
+		"Connection: close\r\n\r",
+		"\nhelloPOST",
+	}
+	for i, chunk := range chunks {
+		_, _ = rb.WriteString(chunk)
+		req, err := p.Parse(rb)
+		if i < len(chunks)-1 {
+			if err != ErrNeedMore || req != nil {
+				t.Fatalf("chunk %d: expect ErrNeedMore but got %v", i, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Method != "GET" || req.URI != "/index.html" || req.Proto != "HTTP/1.1" || req.Major != 1 || req.Minor != 1 {
+			t.Fatalf("unexpected request line %+v", req)
+		}
+		if len(req.Headers) != 3 || req.Header("host") != "example.com" {
+			t.Fatalf("unexpected headers %+v", req.Headers)
+		}
+		if req.ContentLength != 5 || req.Chunked || req.KeepAlive() {
+			t.Fatalf("unexpected request %+v", req)
+		}
+	}
+
+	body := make([]byte, 5)
+	_, _ = rb.Read(body)
+	if string(body) != "hello" {
+		t.Fatalf("expect hello but got %q", body)
+	}
+	if rb.Length() != 4 {
+		t.Fatalf("expect len 4 bytes but got %d", rb.Length())
+	}
+}
+
+func TestParser_Chunked(t *testing.T) {
+	rb := ringbuffer.New(64)
+	_, _ = rb.WriteString("POST /upload HTTP/1.0\nTransfer-Encoding: gzip,\nTransfer-Encoding: chunked\n\n1a;ext=1\r\n")
+
+	var p Parser
+	req, err := p.Parse(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !req.Chunked || req.ContentLength != -1 || req.KeepAlive() {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	size, err := ParseChunkSize(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 0x1a || !rb.IsEmpty() {
+		t.Fatalf("expect 26 but got %d, len %d", size, rb.Length())
+	}
+
+	_, _ = rb.WriteString("0")
+	if _, err = ParseChunkSize(rb); err != ErrNeedMore {
+		t.Fatalf("expect ErrNeedMore but got %v", err)
+	}
+	_, _ = rb.WriteString("\r\n")
+	if size, err = ParseChunkSize(rb); err != nil || size != 0 {
+		t.Fatalf("expect 0 but got %d, err %v", size, err)
+	}
+}
+
+func TestParser_Error(t *testing.T) {
+	for _, head := range []string{
+		"GET /\r\n\r\n",
+		"GET / HTTP/1.1\r\nBad Header: x\r\n\r\n",
+		"GET / FTP/1.1\r\n\r\n",
+		"GET / HTTP/1.1\r\nContent-Length: -1\r\n\r\n",
+		"GET / HTTP/1.1\r\nContent-Length: +5\r\n\r\n",
+		"GET / HTTP/1.1\r\nContent-Length: 5 5\r\n\r\n",
+		"GET / HTTP/1.1\r\nContent-Length:\r\n\r\n",
+		"POST / HTTP/1.1\r\nTransfer-Encoding: gzip\r\nContent-Length: 5\r\n\r\n",
+		"POST / HTTP/1.1\r\nTransfer-Encoding: chunked, gzip\r\n\r\n",
+		"POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\nContent-Length: 5\r\n\r\n",
+		"POST / HTTP/1.1\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\n\r\n",
+	} {
+		rb := ringbuffer.New(64)
+		_, _ = rb.WriteString(head)
+
+		var p Parser
+		if _, err := p.Parse(rb); err != ErrMalformed {
+			t.Fatalf("%q: expect ErrMalformed but got %v", head, err)
+		}
+	}
+
+	rb := ringbuffer.New(64)
+	_, _ = rb.WriteString("GET / HTTP/1.1\r\nHost: example.com\r\n")
+	p := Parser{MaxHeaderSize: 16}
+	if _, err := p.Parse(rb); err != ErrHeaderTooLarge {
+		t.Fatalf("expect ErrHeaderTooLarge but got %v", err)
+	}
+}
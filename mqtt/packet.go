@@ -0,0 +1,175 @@
+// Package mqtt 基于 RingBuffer 的 MQTT 3.1.1/5 控制报文拆包
+package mqtt
+
+import (
+	"errors"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+var (
+	// ErrNeedMore 数据不完整，需要等待更多数据
+	ErrNeedMore = errors.New("mqtt: need more data")
+	// ErrMalformed 报文格式错误
+	ErrMalformed = errors.New("mqtt: malformed packet")
+	// ErrPacketTooLarge 报文超过 MaxPacketSize
+	ErrPacketTooLarge = errors.New("mqtt: packet too large")
+)
+
+// MaxRemainingLength remaining length 可以表示的最大值
+const MaxRemainingLength = 268435455
+
+// PacketType 控制报文类型
+type PacketType byte
+
+const (
+	CONNECT     PacketType = 1
+	CONNACK     PacketType = 2
+	PUBLISH     PacketType = 3
+	PUBACK      PacketType = 4
+	PUBREC      PacketType = 5
+	PUBREL      PacketType = 6
+	PUBCOMP     PacketType = 7
+	SUBSCRIBE   PacketType = 8
+	SUBACK      PacketType = 9
+	UNSUBSCRIBE PacketType = 10
+	UNSUBACK    PacketType = 11
+	PINGREQ     PacketType = 12
+	PINGRESP    PacketType = 13
+	DISCONNECT  PacketType = 14
+	AUTH        PacketType = 15 // MQTT 5
+)
+
+// FixedHeader 固定报头
+type FixedHeader struct {
+	Type  PacketType
+	Flags byte // 低 4 位
+	// RemainingLength 可变报头和有效载荷的长度
+	RemainingLength int
+	// HeaderLength 固定报头本身的长度，2 到 5 字节
+	HeaderLength int
+}
+
+// PacketLength 整个报文的长度
+func (h FixedHeader) PacketLength() int {
+	return h.HeaderLength + h.RemainingLength
+}
+
+// ReadFixedHeader 解析 rb 中的固定报头，不移动读指针
+// remaining length 跨越环尾时也能正确解析，数据不完整时返回 ErrNeedMore
+func ReadFixedHeader(rb *ringbuffer.RingBuffer) (h FixedHeader, err error) {
+	if rb.Length() < 2 {
+		return h, ErrNeedMore
+	}
+
+	b := rb.PeekUint8()
+	h.Type = PacketType(b >> 4)
+	h.Flags = b & 0xf
+	if err = validateFlags(h.Type, h.Flags); err != nil {
+		return h, err
+	}
+
+	multiplier := 1
+	for i := 1; ; i++ {
+		if i > 4 {
+			return h, ErrMalformed
+		}
+		if rb.Length() <= i {
+			return h, ErrNeedMore
+		}
+		b = rb.PeekUint8At(i)
+		h.RemainingLength += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			h.HeaderLength = i + 1
+			return h, nil
+		}
+	}
+}
+
+func validateFlags(t PacketType, flags byte) error {
+	switch t {
+	case 0:
+		return ErrMalformed
+	case PUBLISH:
+		// QoS 不能为 3
+		if flags&0x6 == 0x6 {
+			return ErrMalformed
+		}
+	case PUBREL, SUBSCRIBE, UNSUBSCRIBE:
+		if flags != 0x2 {
+			return ErrMalformed
+		}
+	default:
+		if flags != 0 {
+			return ErrMalformed
+		}
+	}
+	return nil
+}
+
+// Decoder 检测 rb 中完整的控制报文
+type Decoder struct {
+	// MaxPacketSize 报文（包含固定报头）的最大长度，为 0 时不限制
+	MaxPacketSize int
+}
+
+// PeekPacket 查看 rb 中第一个完整的报文，不移动读指针
+// first 和 end 为可变报头和有效载荷，直接引用 rb 的内部缓冲区，处理完后调用 rb.Retrieve(h.PacketLength())
+// 报文不完整时返回 ErrNeedMore，超过 MaxPacketSize 时返回 ErrPacketTooLarge，不需要等待报文接收完
+func (d *Decoder) PeekPacket(rb *ringbuffer.RingBuffer) (h FixedHeader, first, end []byte, err error) {
+	h, err = ReadFixedHeader(rb)
+	if err != nil {
+		return h, nil, nil, err
+	}
+	if d.MaxPacketSize > 0 && h.PacketLength() > d.MaxPacketSize {
+		return h, nil, nil, ErrPacketTooLarge
+	}
+	if rb.Length() < h.PacketLength() {
+		return h, nil, nil, ErrNeedMore
+	}
+
+	first, end = rb.PeekAt(h.HeaderLength, h.RemainingLength)
+	return h, first, end, nil
+}
+
+// ReadPacket 同 PeekPacket，但会拷贝出可变报头和有效载荷，并移动读指针
+func (d *Decoder) ReadPacket(rb *ringbuffer.RingBuffer) (h FixedHeader, body []byte, err error) {
+	h, first, end, err := d.PeekPacket(rb)
+	if err != nil {
+		return h, nil, err
+	}
+
+	body = make([]byte, len(first)+len(end))
+	copy(body, first)
+	copy(body[len(first):], end)
+	rb.Retrieve(h.PacketLength())
+	return h, body, nil
+}
+
+// WriteFixedHeader 将固定报头写入 rb，之后需要写入 remainingLength 字节的可变报头和有效载荷
+// 写入失败（例如超出 Budget）时返回 rb.Write 的错误，rb 不变
+func WriteFixedHeader(rb *ringbuffer.RingBuffer, t PacketType, flags byte, remainingLength int) error {
+	if remainingLength < 0 || remainingLength > MaxRemainingLength {
+		return ErrPacketTooLarge
+	}
+
+	var tmp [5]byte
+	tmp[0] = byte(t)<<4 | flags&0xf
+	n := 1
+	for {
+		b := byte(remainingLength % 128)
+		remainingLength /= 128
+		if remainingLength > 0 {
+			b |= 0x80
+		}
+		tmp[n] = b
+		n++
+		if remainingLength == 0 {
+			break
+		}
+	}
+
+	_, err := rb.Write(tmp[:n])
+	return err
+}
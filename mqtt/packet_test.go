@@ -0,0 +1,120 @@
+package mqtt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+func TestWriteFixedHeader(t *testing.T) {
+	for _, tc := range []struct {
+		length int
+		expect []byte
+	}{
+		{0, []byte{0x30, 0x00}},
+		{127, []byte{0x30, 0x7f}},
+		{128, []byte{0x30, 0x80, 0x01}},
+		{16383, []byte{0x30, 0xff, 0x7f}},
+		{16384, []byte{0x30, 0x80, 0x80, 0x01}},
+		{MaxRemainingLength, []byte{0x30, 0xff, 0xff, 0xff, 0x7f}},
+	} {
+		rb := ringbuffer.New(8)
+		if err := WriteFixedHeader(rb, PUBLISH, 0, tc.length); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(rb.Bytes(), tc.expect) {
+			t.Fatalf("expect %x but got %x", tc.expect, rb.Bytes())
+		}
+
+		h, err := ReadFixedHeader(rb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Type != PUBLISH || h.RemainingLength != tc.length || h.HeaderLength != len(tc.expect) {
+			t.Fatalf("unexpected header %+v", h)
+		}
+	}
+
+	if err := WriteFixedHeader(ringbuffer.New(8), PUBLISH, 0, MaxRemainingLength+1); err != ErrPacketTooLarge {
+		t.Fatalf("expect ErrPacketTooLarge but got %v", err)
+	}
+
+	rb, err := ringbuffer.NewWithBudget(0, ringbuffer.NewBudget(1, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteFixedHeader(rb, PUBLISH, 0, 128); err != ringbuffer.ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect nothing written but got %x", rb.Bytes())
+	}
+}
+
+func TestDecoder_PeekPacket(t *testing.T) {
+	rb := ringbuffer.New(256)
+	_, _ = rb.Write(make([]byte, 100))
+	_, _ = rb.Read(make([]byte, 100))
+
+	body := bytes.Repeat([]byte("x"), 200)
+	_ = WriteFixedHeader(rb, PUBLISH, 0x3, len(body))
+
+	d := &Decoder{MaxPacketSize: 1024}
+	if _, _, _, err := d.PeekPacket(rb); err != ErrNeedMore {
+		t.Fatalf("expect ErrNeedMore but got %v", err)
+	}
+
+	_, _ = rb.Write(body)
+	_ = WriteFixedHeader(rb, PINGREQ, 0, 0)
+
+	h, first, end, err := d.PeekPacket(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Type != PUBLISH || h.Flags != 0x3 || h.HeaderLength != 3 {
+		t.Fatalf("unexpected header %+v", h)
+	}
+	if len(end) == 0 || !bytes.Equal(append(append([]byte{}, first...), end...), body) {
+		t.Fatalf("unexpected body %d %d", len(first), len(end))
+	}
+	rb.Retrieve(h.PacketLength())
+
+	h, b, err := d.ReadPacket(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Type != PINGREQ || len(b) != 0 || !rb.IsEmpty() {
+		t.Fatalf("unexpected packet %+v", h)
+	}
+}
+
+func TestDecoder_Error(t *testing.T) {
+	d := &Decoder{MaxPacketSize: 16}
+
+	rb := ringbuffer.New(8)
+	_ = WriteFixedHeader(rb, PUBLISH, 0, 100)
+	if _, _, _, err := d.PeekPacket(rb); err != ErrPacketTooLarge {
+		t.Fatalf("expect ErrPacketTooLarge but got %v", err)
+	}
+
+	for _, data := range [][]byte{
+		{0x00, 0x00},
+		{0x80, 0x00},
+		{0x60, 0x00},
+		{0x36, 0x00},
+		{0x30, 0xff, 0xff, 0xff, 0xff, 0x01},
+	} {
+		rb = ringbuffer.New(8)
+		_, _ = rb.Write(data)
+		if _, _, _, err := d.PeekPacket(rb); err != ErrMalformed {
+			t.Fatalf("%x: expect ErrMalformed but got %v", data, err)
+		}
+	}
+
+	rb = ringbuffer.New(8)
+	_, _ = rb.Write([]byte{0x30, 0x80})
+	if _, err := ReadFixedHeader(rb); err != ErrNeedMore {
+		t.Fatalf("expect ErrNeedMore but got %v", err)
+	}
+}
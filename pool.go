@@ -39,25 +39,126 @@ const (
 
 	calibrateCallsThreshold = 42000
 	maxPercentile           = 0.95
+
+	// maxSteps 自定义 size class 时最多的档位数
+	maxSteps = 40
 )
 
+// PoolOptions Pool 的可选配置，零值字段使用默认值
+type PoolOptions struct {
+	// CalibrateCallsThreshold 累计多少次 Put 之后重新校准，默认 42000
+	CalibrateCallsThreshold uint64
+	// MaxPercentile 校准时 maxSize 覆盖的 Put 调用比例，取值 (0, 1]，默认 0.95
+	MaxPercentile float64
+	// MinSize 最小的 size class，向上取整到 2 的幂，默认 64
+	MinSize int
+	// MaxSize 最大的 size class，向上取整到 2 的幂，默认 32MB
+	MaxSize int
+	// DefaultSize 第一次校准前 Get 新建 RingBuffer 的大小，默认 0
+	DefaultSize int
+	// Allocator 新建 RingBuffer 时使用的分配器，默认使用 make
+	// Put 时因为超过 maxSize 被丢弃的 RingBuffer 会调用 Free 归还底层数组，
+	// 在池中闲置、之后被 sync.Pool 清理的 RingBuffer 会在被 GC 回收时归还底层数组
+	Allocator Allocator
+	// RetainCapacity 为 true 时 Put 不再调用 Reset 缩容，保留扩容后的底层数组，
+	// 仅清空读写指针，长期处理大数据量的连接不必反复扩容
+	// 保留的容量不超过校准出的 maxSize，第一次校准前不超过最大的 size class，超过时依然调用 Reset
+	// 保留了容量的 RingBuffer 不按 size class 存放，Get 不论其容量大小都会优先复用，
+	// GetWithCapacity 在对应的 size class 中没有时也会复用容量足够的 RingBuffer
+	RetainCapacity bool
+}
+
 // Pool represents byte buffer pool.
 //
 // Distinct pools may be used for distinct types of byte buffers.
 // Properly determined byte buffer types with their own pools may help reducing
 // memory waste.
 type Pool struct {
-	calls       [steps]uint64
+	calls       [maxSteps]uint64
 	calibrating uint64
 
+	gets         uint64
+	puts         uint64
+	hits         uint64
+	drops        uint64
+	calibrations uint64
+
 	defaultSize uint64
 	maxSize     uint64
 
-	pool sync.Pool
+	calibrateCallsThreshold uint64
+	maxPercentile           float64
+	minBitSize              int
+	steps                   int
+	retainCapacity          bool
+	allocator               Allocator
+
+	pool    sync.Pool
+	classes [maxSteps]sync.Pool // 按容量分档的子池，第 i 档中 RingBuffer 的容量不小于第 i 档的大小
 }
 
 var defaultPool Pool
 
+// NewPool 使用 opts 创建 Pool，Pool 的零值等价于 NewPool(PoolOptions{})
+func NewPool(opts PoolOptions) *Pool {
+	p := &Pool{
+		calibrateCallsThreshold: opts.CalibrateCallsThreshold,
+		maxPercentile:           opts.MaxPercentile,
+		retainCapacity:          opts.RetainCapacity,
+		allocator:               opts.Allocator,
+	}
+	if p.maxPercentile < 0 || p.maxPercentile > 1 {
+		p.maxPercentile = 0
+	}
+
+	if opts.MinSize > 0 || opts.MaxSize > 0 {
+		p.minBitSize = minBitSize
+		if opts.MinSize > 0 {
+			p.minBitSize = bitSize(opts.MinSize)
+		}
+		maxBitSize := minBitSize + steps - 1
+		if opts.MaxSize > 0 {
+			maxBitSize = bitSize(opts.MaxSize)
+		}
+		p.steps = maxBitSize - p.minBitSize + 1
+		if p.steps < 1 {
+			p.steps = 1
+		}
+		if p.steps > maxSteps {
+			p.steps = maxSteps
+		}
+	}
+
+	if opts.DefaultSize > 0 {
+		p.defaultSize = uint64(opts.DefaultSize)
+	}
+	return p
+}
+
+// bitSize 返回不小于 n 的最小的 2 的幂的指数
+func bitSize(n int) int {
+	b := 0
+	for 1<<uint(b) < n {
+		b++
+	}
+	return b
+}
+
+func (p *Pool) config() (threshold uint64, percentile float64, minBits int, stepCount int) {
+	threshold, percentile = p.calibrateCallsThreshold, p.maxPercentile
+	minBits, stepCount = p.minBitSize, p.steps
+	if threshold == 0 {
+		threshold = calibrateCallsThreshold
+	}
+	if percentile == 0 {
+		percentile = maxPercentile
+	}
+	if stepCount == 0 {
+		minBits, stepCount = minBitSize, steps
+	}
+	return
+}
+
 // GetFromPool returns an empty byte buffer from the pool.
 //
 // Got byte buffer may be returned to the pool via Put call.
@@ -70,14 +171,80 @@ func GetFromPool() *RingBuffer { return defaultPool.Get() }
 // The byte buffer may be returned to the pool via Put after the use
 // in order to minimize GC overhead.
 func (p *Pool) Get() *RingBuffer {
+	b := p.get()
+	b.attach(p)
+	debugTrackGet(b)
+	return b
+}
+
+func (p *Pool) get() *RingBuffer {
+	atomic.AddUint64(&p.gets, 1)
+	defaultSize := int(atomic.LoadUint64(&p.defaultSize))
+
+	if idx, ok := p.classIndex(defaultSize); ok {
+		if v := p.classes[idx].Get(); v != nil {
+			atomic.AddUint64(&p.hits, 1)
+			return v.(*RingBuffer)
+		}
+	}
 	v := p.pool.Get()
 	if v != nil {
+		atomic.AddUint64(&p.hits, 1)
 		return v.(*RingBuffer)
 	}
 
-	return New(int(atomic.LoadUint64(&p.defaultSize)))
+	return p.newBuffer(defaultSize)
 }
 
+// GetWithCapacity 返回一个容量不小于 n 的 RingBuffer
+//
+// 会从 n 对应的 size class 子池中获取，新建时使用该 size class 的大小，
+// 超过最大 size class 时直接新建容量为 n 的 RingBuffer
+func (p *Pool) GetWithCapacity(n int) *RingBuffer {
+	b := p.getWithCapacity(n)
+	b.attach(p)
+	debugTrackGet(b)
+	return b
+}
+
+func (p *Pool) getWithCapacity(n int) *RingBuffer {
+	atomic.AddUint64(&p.gets, 1)
+	_, _, minBits, stepCount := p.config()
+
+	idx := index(n, minBits, stepCount)
+	size := 1 << uint(minBits+idx)
+	if n <= size {
+		if v := p.classes[idx].Get(); v != nil {
+			atomic.AddUint64(&p.hits, 1)
+			return v.(*RingBuffer)
+		}
+	} else {
+		size = n
+	}
+
+	if p.retainCapacity {
+		if v := p.pool.Get(); v != nil {
+			b := v.(*RingBuffer)
+			if b.size >= n {
+				atomic.AddUint64(&p.hits, 1)
+				return b
+			}
+			p.pool.Put(b)
+		}
+	}
+	return p.newBuffer(size)
+}
+
+func (p *Pool) newBuffer(size int) *RingBuffer {
+	if p.allocator == nil {
+		return New(size)
+	}
+	return NewWithAllocator(size, p.allocator)
+}
+
+// GetFromPoolWithCapacity 从默认 Pool 中获取容量不小于 n 的 RingBuffer
+func GetFromPoolWithCapacity(n int) *RingBuffer { return defaultPool.GetWithCapacity(n) }
+
 // PutInPool returns byte buffer to the pool.
 //
 // ByteBuffer.B mustn't be touched after returning it to the pool.
@@ -87,33 +254,83 @@ func PutInPool(b *RingBuffer) { defaultPool.Put(b) }
 // Put releases byte buffer obtained via Get to the pool.
 //
 // The buffer mustn't be accessed after returning to the pool.
+// Put panics if the buffer is still retained by other holders, use Release instead.
 func (p *Pool) Put(b *RingBuffer) {
-	idx := index(len(b.buf))
+	b.checkUse()
+	b.detach()
+	threshold, _, minBits, stepCount := p.config()
+	idx := index(len(b.buf), minBits, stepCount)
+	atomic.AddUint64(&p.puts, 1)
 
-	if atomic.AddUint64(&p.calls[idx], 1) > calibrateCallsThreshold {
+	if atomic.AddUint64(&p.calls[idx], 1) > threshold {
 		p.calibrate()
 	}
 
 	maxSize := int(atomic.LoadUint64(&p.maxSize))
 	if maxSize == 0 || cap(b.buf) <= maxSize {
-		b.Reset()
-		p.pool.Put(b)
+		retainSize := maxSize
+		if retainSize == 0 {
+			retainSize = 1 << uint(minBits+stepCount-1)
+		}
+		retained := p.retainCapacity && b.size <= retainSize
+		if retained {
+			b.RetrieveAll()
+		} else {
+			b.Reset()
+		}
+		if !debugTrackPut(b) {
+			return
+		}
+		if b.alloc != nil {
+			// 池中的 RingBuffer 没有还在使用的切片，被 sync.Pool 清理后可以安全地由 finalizer 归还底层数组
+			b.pooled = true
+			b.ensureFinalizer()
+		}
+		if retained {
+			p.pool.Put(b)
+		} else if idx, ok := p.classIndex(b.size); ok {
+			p.classes[idx].Put(b)
+		} else {
+			p.pool.Put(b)
+		}
+	} else {
+		atomic.AddUint64(&p.drops, 1)
+		if b.alloc != nil {
+			// 丢弃的 RingBuffer 不会再被使用，不受 GC 管理的内存需要显式归还
+			b.Free()
+		}
+		debugTrackPut(b)
 	}
 }
 
+// classIndex 返回不大于 n 的最大 size class，n 小于最小的 size class 时 ok 为 false
+func (p *Pool) classIndex(n int) (idx int, ok bool) {
+	_, _, minBits, stepCount := p.config()
+	if n < 1<<uint(minBits) {
+		return 0, false
+	}
+
+	idx = index(n, minBits, stepCount)
+	if 1<<uint(minBits+idx) > n {
+		idx--
+	}
+	return idx, true
+}
+
 func (p *Pool) calibrate() {
 	if !atomic.CompareAndSwapUint64(&p.calibrating, 0, 1) {
 		return
 	}
 
-	a := make(callSizes, 0, steps)
+	_, percentile, minBits, stepCount := p.config()
+	a := make(callSizes, 0, stepCount)
 	var callsSum uint64
-	for i := uint64(0); i < steps; i++ {
+	for i := 0; i < stepCount; i++ {
 		calls := atomic.SwapUint64(&p.calls[i], 0)
 		callsSum += calls
 		a = append(a, callSize{
 			calls: calls,
-			size:  minSize << i,
+			size:  1 << uint(minBits+i),
 		})
 	}
 	sort.Sort(a)
@@ -121,9 +338,9 @@ func (p *Pool) calibrate() {
 	defaultSize := a[0].size
 	maxSize := defaultSize
 
-	maxSum := uint64(float64(callsSum) * maxPercentile)
+	maxSum := uint64(float64(callsSum) * percentile)
 	callsSum = 0
-	for i := 0; i < steps; i++ {
+	for i := 0; i < stepCount; i++ {
 		if callsSum > maxSum {
 			break
 		}
@@ -136,10 +353,65 @@ func (p *Pool) calibrate() {
 
 	atomic.StoreUint64(&p.defaultSize, defaultSize)
 	atomic.StoreUint64(&p.maxSize, maxSize)
+	atomic.AddUint64(&p.calibrations, 1)
 
 	atomic.StoreUint64(&p.calibrating, 0)
 }
 
+// PoolStats Pool 的统计信息
+type PoolStats struct {
+	// Gets Get 调用次数
+	Gets uint64
+	// Puts Put 调用次数
+	Puts uint64
+	// Hits Get 从池中取到 RingBuffer 的次数
+	Hits uint64
+	// Allocs Get 新建 RingBuffer 的次数
+	Allocs uint64
+	// Drops Put 时因为超过 MaxSize 而丢弃的次数
+	Drops uint64
+	// Calibrations 校准次数
+	Calibrations uint64
+
+	// DefaultSize 当前校准出的 Get 新建 RingBuffer 的大小
+	DefaultSize uint64
+	// MaxSize 当前校准出的可以放回池中的最大容量，为 0 表示还未校准
+	MaxSize uint64
+
+	// SizeClasses 各档位的大小
+	SizeClasses []int
+	// Calls 自上次校准以来各档位 Put 的次数，与 SizeClasses 一一对应
+	Calls []uint64
+}
+
+// Stats 返回 Pool 的统计信息，各字段分别原子读取，并发调用时彼此之间可能不完全一致
+func (p *Pool) Stats() PoolStats {
+	_, _, minBits, stepCount := p.config()
+
+	s := PoolStats{
+		Gets:         atomic.LoadUint64(&p.gets),
+		Puts:         atomic.LoadUint64(&p.puts),
+		Hits:         atomic.LoadUint64(&p.hits),
+		Drops:        atomic.LoadUint64(&p.drops),
+		Calibrations: atomic.LoadUint64(&p.calibrations),
+		DefaultSize:  atomic.LoadUint64(&p.defaultSize),
+		MaxSize:      atomic.LoadUint64(&p.maxSize),
+		SizeClasses:  make([]int, stepCount),
+		Calls:        make([]uint64, stepCount),
+	}
+	if s.Gets > s.Hits {
+		s.Allocs = s.Gets - s.Hits
+	}
+	for i := 0; i < stepCount; i++ {
+		s.SizeClasses[i] = 1 << uint(minBits+i)
+		s.Calls[i] = atomic.LoadUint64(&p.calls[i])
+	}
+	return s
+}
+
+// GetPoolStats 返回默认 Pool 的统计信息
+func GetPoolStats() PoolStats { return defaultPool.Stats() }
+
 type callSize struct {
 	calls uint64
 	size  uint64
@@ -159,9 +431,9 @@ func (ci callSizes) Swap(i, j int) {
 	ci[i], ci[j] = ci[j], ci[i]
 }
 
-func index(n int) int {
+func index(n int, minBitSize int, steps int) int {
 	n--
-	n >>= minBitSize
+	n >>= uint(minBitSize)
 	idx := 0
 	for n > 0 {
 		n >>= 1
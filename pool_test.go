@@ -33,3 +33,190 @@ func TestPool(t *testing.T) {
 	}
 	<-stop
 }
+
+func TestNewPool(t *testing.T) {
+	p := NewPool(PoolOptions{
+		CalibrateCallsThreshold: 10,
+		MaxPercentile:           0.5,
+		MinSize:                 100,
+		MaxSize:                 1000,
+		DefaultSize:             256,
+	})
+
+	buf := p.Get()
+	if buf.Capacity() != 256 {
+		t.Fatalf("expect capacity 256 but got %d", buf.Capacity())
+	}
+
+	// size class: 128, 256, 512, 1024
+	for i := 0; i < 11; i++ {
+		p.Put(New(600))
+	}
+	if p.defaultSize != 1024 || p.maxSize != 1024 {
+		t.Fatalf("expect calibrated size 1024 but got %d %d", p.defaultSize, p.maxSize)
+	}
+
+	for i := 0; i < 11; i++ {
+		p.Put(New(1 << 20))
+	}
+	if p.defaultSize != 1024 {
+		t.Fatalf("expect calibrated size 1024 but got %d", p.defaultSize)
+	}
+}
+
+func TestPool_index(t *testing.T) {
+	for _, tc := range []struct {
+		n, minBitSize, steps, expect int
+	}{
+		{0, minBitSize, steps, 0},
+		{64, minBitSize, steps, 0},
+		{65, minBitSize, steps, 1},
+		{maxSize, minBitSize, steps, steps - 1},
+		{maxSize * 4, minBitSize, steps, steps - 1},
+		{129, 7, 4, 1},
+		{1 << 20, 7, 4, 3},
+	} {
+		if idx := index(tc.n, tc.minBitSize, tc.steps); idx != tc.expect {
+			t.Fatalf("index(%d, %d, %d): expect %d but got %d", tc.n, tc.minBitSize, tc.steps, tc.expect, idx)
+		}
+	}
+}
+
+func TestPool_Stats(t *testing.T) {
+	p := NewPool(PoolOptions{CalibrateCallsThreshold: 4, MinSize: 64, MaxSize: 256})
+
+	// sync.Pool 在 race 模式下会随机丢弃，因此只检查总数
+	bufs := []*RingBuffer{p.Get(), p.Get(), p.Get()}
+	for _, b := range bufs {
+		_, _ = b.Write(make([]byte, 100))
+		p.Put(b)
+	}
+	p.Put(New(64))
+
+	s := p.Stats()
+	if s.Gets != 3 || s.Puts != 4 || s.Hits+s.Allocs != 3 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if len(s.SizeClasses) != 3 || s.SizeClasses[0] != 64 || s.SizeClasses[2] != 256 {
+		t.Fatalf("unexpected size classes %v", s.SizeClasses)
+	}
+	if s.Calls[0] != 1 || s.Calls[1] != 3 || s.Calibrations != 0 {
+		t.Fatalf("unexpected calls %v", s.Calls)
+	}
+
+	p.Put(New(128))
+	p.Put(New(128))
+	s = p.Stats()
+	if s.Calibrations != 1 || s.DefaultSize != 128 || s.MaxSize != 128 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	p.Put(New(256))
+	s = p.Stats()
+	if s.Drops != 1 {
+		t.Fatalf("expect 1 drop but got %d", s.Drops)
+	}
+}
+
+func TestPool_GetWithCapacity(t *testing.T) {
+	p := NewPool(PoolOptions{MinSize: 64, MaxSize: 1024})
+
+	for _, tc := range []struct {
+		n, expect int
+	}{
+		{0, 64},
+		{64, 64},
+		{100, 128},
+		{1000, 1024},
+		{5000, 5000},
+	} {
+		b := p.GetWithCapacity(tc.n)
+		if b.Capacity() != tc.expect {
+			t.Fatalf("GetWithCapacity(%d): expect capacity %d but got %d", tc.n, tc.expect, b.Capacity())
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		b := p.GetWithCapacity(300)
+		if b.Capacity() < 300 {
+			t.Fatalf("expect capacity >= 300 but got %d", b.Capacity())
+		}
+		_, _ = b.Write(make([]byte, 100))
+		p.Put(b)
+
+		// 容量较小的 RingBuffer 不应被 GetWithCapacity 取到
+		p.Put(New(200))
+	}
+
+	if idx, ok := p.classIndex(300); !ok || idx != 2 {
+		t.Fatalf("expect class 2 but got %d %v", idx, ok)
+	}
+	if _, ok := p.classIndex(63); ok {
+		t.Fatal("expect no class for 63")
+	}
+}
+
+func TestPool_RetainCapacity(t *testing.T) {
+	if DebugEnabled {
+		t.Skip("buffers are not reused after Put in debug builds")
+	}
+
+	// 第一次校准前 defaultSize 为 0 或者与扩容后的容量不在同一个 size class，Get 依然可以复用
+	for _, opts := range []PoolOptions{
+		{RetainCapacity: true},
+		{DefaultSize: 64, RetainCapacity: true},
+	} {
+		p := NewPool(opts)
+		var prev *RingBuffer
+		reused := 0
+		for i := 0; i < 5; i++ {
+			b := p.Get()
+			if b == prev {
+				reused++
+				if b.Capacity() < 4096 || !b.IsEmpty() {
+					t.Fatalf("expect retained capacity but got %d, len %d", b.Capacity(), b.Length())
+				}
+			}
+			_, _ = b.Write(make([]byte, 4096))
+			prev = b
+			p.Put(b)
+		}
+		// sync.Pool 在 race 模式下会随机丢弃，因此不要求每次都命中
+		if s := p.Stats(); reused == 0 || s.Hits == 0 || s.Allocs == 5 {
+			t.Fatalf("expect retained buffer reused but got %+v", s)
+		}
+
+		// GetWithCapacity 同样可以复用容量足够的 RingBuffer
+		b := p.GetWithCapacity(2048)
+		if b.Capacity() < 2048 {
+			t.Fatalf("expect capacity >= 2048 but got %d", b.Capacity())
+		}
+		p.Put(b)
+	}
+
+	// 不保留容量时 Put 会缩容
+	p := NewPool(PoolOptions{})
+	b := p.GetWithCapacity(64)
+	_, _ = b.Write(make([]byte, 4000))
+	p.Put(b)
+	for i := 0; i < 5; i++ {
+		b = p.GetWithCapacity(64)
+		if b.Capacity() != 64 {
+			t.Fatalf("expect capacity 64 but got %d", b.Capacity())
+		}
+		p.Put(b)
+	}
+
+	// 第一次校准前，超过最大 size class 的容量不会被保留
+	p = NewPool(PoolOptions{MinSize: 64, MaxSize: 1024, RetainCapacity: true})
+	b = p.GetWithCapacity(64)
+	_, _ = b.Write(make([]byte, 4000))
+	p.Put(b)
+	for i := 0; i < 5; i++ {
+		b = p.GetWithCapacity(64)
+		if b.Capacity() != 64 {
+			t.Fatalf("expect capacity 64 but got %d", b.Capacity())
+		}
+		p.Put(b)
+	}
+}
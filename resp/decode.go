@@ -0,0 +1,150 @@
+package resp
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+// Decode 从 rb 中解码一个完整的 RESP 数据
+// 使用 VirtualRead 读取，数据不完整时 VirtualRevert 并返回 ErrNeedMore，读指针不变；
+// 解码成功时 VirtualFlush 移动读指针
+// Decode 开始时会 VirtualRevert，丢弃调用者之前的虚读
+func Decode(rb *ringbuffer.RingBuffer) (v Value, err error) {
+	rb.VirtualRevert()
+
+	v, err = decode(rb, 0)
+	if err != nil {
+		rb.VirtualRevert()
+		return Value{}, err
+	}
+
+	rb.VirtualFlush()
+	return v, nil
+}
+
+func decode(rb *ringbuffer.RingBuffer, depth int) (v Value, err error) {
+	if depth > MaxDepth {
+		return v, ErrProtocol
+	}
+
+	line, err := readLine(rb)
+	if err != nil {
+		return v, err
+	}
+	if len(line) == 0 {
+		return v, ErrProtocol
+	}
+
+	v.Type = Type(line[0])
+	line = line[1:]
+	switch {
+	case v.Type == SimpleString || v.Type == Error || v.Type == Double || v.Type == BigNumber:
+		v.Str = line
+	case v.Type == Integer:
+		v.Int, err = parseInt(line)
+	case v.Type == Null:
+		if len(line) != 0 {
+			return v, ErrProtocol
+		}
+		v.IsNull = true
+	case v.Type == Boolean:
+		if len(line) != 1 || (line[0] != 't' && line[0] != 'f') {
+			return v, ErrProtocol
+		}
+		if line[0] == 't' {
+			v.Int = 1
+		}
+	case v.Type.bulk():
+		v.Str, v.IsNull, err = readBulk(rb, line)
+	case v.Type.aggregate():
+		v.Elems, v.IsNull, err = readAggregate(rb, v.Type, line, depth)
+	default:
+		return v, ErrProtocol
+	}
+	return v, err
+}
+
+func readBulk(rb *ringbuffer.RingBuffer, line []byte) (b []byte, isNull bool, err error) {
+	n, err := parseInt(line)
+	if err != nil {
+		return nil, false, err
+	}
+	if n == -1 {
+		return nil, true, nil
+	}
+	if n < 0 || n > MaxBulkLength {
+		return nil, false, ErrProtocol
+	}
+	if int64(rb.VirtualLength()) < n+2 {
+		return nil, false, ErrNeedMore
+	}
+
+	b = make([]byte, n+2)
+	_, _ = rb.VirtualRead(b)
+	if b[n] != '\r' || b[n+1] != '\n' {
+		return nil, false, ErrProtocol
+	}
+	return b[:n], false, nil
+}
+
+func readAggregate(rb *ringbuffer.RingBuffer, t Type, line []byte, depth int) (elems []Value, isNull bool, err error) {
+	n, err := parseInt(line)
+	if err != nil {
+		return nil, false, err
+	}
+	if n == -1 && (t == Array || t == Set || t == Push) {
+		return nil, true, nil
+	}
+	if t == Map || t == Attribute {
+		n *= 2
+	}
+	if n < 0 || n > MaxBulkLength {
+		return nil, false, ErrProtocol
+	}
+	// 每个元素至少 3 字节，可以提前发现数据不完整，也避免恶意的长度导致申请过多内存
+	if n*3 > int64(rb.VirtualLength()) {
+		return nil, false, ErrNeedMore
+	}
+
+	elems = make([]Value, n)
+	for i := range elems {
+		elems[i], err = decode(rb, depth+1)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return elems, false, nil
+}
+
+// readLine 虚读一行，返回的数据不包含 "\r\n"
+func readLine(rb *ringbuffer.RingBuffer) ([]byte, error) {
+	offset := rb.Length() - rb.VirtualLength()
+	first, end := rb.PeekAt(offset, rb.VirtualLength())
+
+	i := bytes.IndexByte(first, '\n')
+	if i < 0 {
+		if j := bytes.IndexByte(end, '\n'); j >= 0 {
+			i = len(first) + j
+		}
+	}
+	if i < 0 {
+		return nil, ErrNeedMore
+	}
+
+	line := make([]byte, i+1)
+	_, _ = rb.VirtualRead(line)
+	if i == 0 || line[i-1] != '\r' {
+		return nil, ErrProtocol
+	}
+	return line[:i-1], nil
+}
+
+func parseInt(b []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, ErrProtocol
+	}
+	return n, nil
+}
@@ -0,0 +1,110 @@
+package resp
+
+import (
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+func TestDecode(t *testing.T) {
+	data := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$5\r\nhello\r\n" +
+		"+OK\r\n-ERR bad\r\n:-42\r\n$-1\r\n*-1\r\n" +
+		"%1\r\n+a\r\n~2\r\n#t\r\n_\r\n,3.14\r\n(123456789012345678901234567890\r\n=7\r\ntxt:abc\r\n"
+
+	// 逐字节写入，每次都尝试解码
+	rb := ringbuffer.New(8)
+	var values []Value
+	for i := 0; i < len(data); i++ {
+		_ = rb.WriteByte(data[i])
+		for {
+			v, err := Decode(rb)
+			if err == ErrNeedMore {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, v)
+		}
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect empty but got len %d", rb.Length())
+	}
+	if len(values) != 10 {
+		t.Fatalf("expect 10 values but got %d", len(values))
+	}
+
+	cmd := values[0]
+	if cmd.Type != Array || len(cmd.Elems) != 3 || string(cmd.Elems[0].Str) != "SET" || string(cmd.Elems[2].Str) != "hello" {
+		t.Fatalf("unexpected command %+v", cmd)
+	}
+	if values[1].Type != SimpleString || string(values[1].Str) != "OK" {
+		t.Fatalf("unexpected %+v", values[1])
+	}
+	if values[2].Type != Error || string(values[2].Str) != "ERR bad" {
+		t.Fatalf("unexpected %+v", values[2])
+	}
+	if values[3].Type != Integer || values[3].Int != -42 {
+		t.Fatalf("unexpected %+v", values[3])
+	}
+	if !values[4].IsNull || values[4].Type != BulkString || !values[5].IsNull || values[5].Type != Array {
+		t.Fatalf("unexpected %+v %+v", values[4], values[5])
+	}
+
+	m := values[6]
+	if m.Type != Map || len(m.Elems) != 2 {
+		t.Fatalf("unexpected map %+v", m)
+	}
+	set := m.Elems[1]
+	if set.Type != Set || len(set.Elems) != 2 || set.Elems[0].Int != 1 || !set.Elems[1].IsNull {
+		t.Fatalf("unexpected set %+v", set)
+	}
+	if values[7].Type != Double || string(values[7].Str) != "3.14" {
+		t.Fatalf("unexpected %+v", values[7])
+	}
+	if values[8].Type != BigNumber {
+		t.Fatalf("unexpected %+v", values[8])
+	}
+	if values[9].Type != VerbatimString || string(values[9].Str) != "txt:abc" {
+		t.Fatalf("unexpected %+v", values[9])
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	for _, data := range []string{
+		"?abc\r\n",
+		":12a\r\n",
+		"+OK\n",
+		"$3\r\nabcd\r\n",
+		"*-2\r\n",
+		"#x\r\n",
+	} {
+		rb := ringbuffer.New(16)
+		_, _ = rb.WriteString(data)
+		if _, err := Decode(rb); err != ErrProtocol {
+			t.Fatalf("%q: expect ErrProtocol but got %v", data, err)
+		}
+		if rb.Length() != len(data) {
+			t.Fatalf("%q: expect len %d but got %d", data, len(data), rb.Length())
+		}
+	}
+}
+
+func TestDecodeFullBuffer(t *testing.T) {
+	rb := ringbuffer.New(7)
+	_, _ = rb.WriteString("+PONG\r\n")
+	if !rb.IsFull() {
+		t.Fatal(rb.Length())
+	}
+
+	v, err := Decode(rb)
+	if err != nil || v.Type != SimpleString || string(v.Str) != "PONG" {
+		t.Fatalf("unexpected %+v, err %v", v, err)
+	}
+	if !rb.IsEmpty() || rb.Length() != 0 {
+		t.Fatalf("expect empty but got len %d", rb.Length())
+	}
+	if _, err = Decode(rb); err != ErrNeedMore {
+		t.Fatalf("expect ErrNeedMore but got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package resp
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+var crlf = []byte("\r\n")
+
+// 所有 Write 函数都会先通过 rb.Grow 预留完整编码后的长度，
+// 预留失败（例如超出 Budget）时返回错误，rb 中不会留下写了一半的数据
+
+// WriteSimpleString 写入 "+s\r\n"
+func WriteSimpleString(rb *ringbuffer.RingBuffer, s string) error {
+	return writeLine(rb, SimpleString, s)
+}
+
+// WriteError 写入 "-msg\r\n"
+func WriteError(rb *ringbuffer.RingBuffer, msg string) error {
+	return writeLine(rb, Error, msg)
+}
+
+// WriteInteger 写入 ":n\r\n"
+func WriteInteger(rb *ringbuffer.RingBuffer, n int64) error {
+	return writeInt(rb, Integer, n)
+}
+
+// WriteBulkString 写入 "$len\r\nb\r\n"
+func WriteBulkString(rb *ringbuffer.RingBuffer, b []byte) error {
+	var tmp [24]byte
+	h := appendInt(tmp[:0], BulkString, int64(len(b)))
+	if err := rb.Grow(len(h) + len(b) + len(crlf)); err != nil {
+		return err
+	}
+
+	_, _ = rb.Write(h)
+	_, _ = rb.Write(b)
+	_, _ = rb.Write(crlf)
+	return nil
+}
+
+// WriteBulkStringString 同 WriteBulkString，参数为 string
+func WriteBulkStringString(rb *ringbuffer.RingBuffer, s string) error {
+	var tmp [24]byte
+	h := appendInt(tmp[:0], BulkString, int64(len(s)))
+	if err := rb.Grow(len(h) + len(s) + len(crlf)); err != nil {
+		return err
+	}
+
+	_, _ = rb.Write(h)
+	_, _ = rb.WriteString(s)
+	_, _ = rb.Write(crlf)
+	return nil
+}
+
+// WriteNullBulkString 写入 RESP2 的 null bulk string "$-1\r\n"
+func WriteNullBulkString(rb *ringbuffer.RingBuffer) error {
+	return writeInt(rb, BulkString, -1)
+}
+
+// WriteNullArray 写入 RESP2 的 null array "*-1\r\n"
+func WriteNullArray(rb *ringbuffer.RingBuffer) error {
+	return writeInt(rb, Array, -1)
+}
+
+// WriteArrayHeader 写入 "*n\r\n"，之后需要写入 n 个元素
+func WriteArrayHeader(rb *ringbuffer.RingBuffer, n int) error {
+	return writeInt(rb, Array, int64(n))
+}
+
+// WriteNull 写入 RESP3 的 "_\r\n"
+func WriteNull(rb *ringbuffer.RingBuffer) error {
+	return writeLine(rb, Null, "")
+}
+
+// WriteBoolean 写入 RESP3 的 "#t\r\n" 或 "#f\r\n"
+func WriteBoolean(rb *ringbuffer.RingBuffer, b bool) error {
+	if b {
+		return writeLine(rb, Boolean, "t")
+	}
+	return writeLine(rb, Boolean, "f")
+}
+
+// WriteDouble 写入 RESP3 的 ",f\r\n"，无穷大和 NaN 分别写入 inf、-inf、nan
+func WriteDouble(rb *ringbuffer.RingBuffer, f float64) error {
+	var tmp [32]byte
+	b := append(tmp[:0], byte(Double))
+	switch {
+	case math.IsInf(f, 1):
+		b = append(b, "inf"...)
+	case math.IsInf(f, -1):
+		b = append(b, "-inf"...)
+	case math.IsNaN(f):
+		b = append(b, "nan"...)
+	default:
+		b = strconv.AppendFloat(b, f, 'g', -1, 64)
+	}
+	b = append(b, crlf...)
+	_, err := rb.Write(b)
+	return err
+}
+
+// WriteMapHeader 写入 RESP3 的 "%n\r\n"，之后需要写入 n 对 key、value
+func WriteMapHeader(rb *ringbuffer.RingBuffer, n int) error {
+	return writeInt(rb, Map, int64(n))
+}
+
+// WriteSetHeader 写入 RESP3 的 "~n\r\n"，之后需要写入 n 个元素
+func WriteSetHeader(rb *ringbuffer.RingBuffer, n int) error {
+	return writeInt(rb, Set, int64(n))
+}
+
+// WriteValue 按 v.Type 写入 v，聚合类型会整体预留空间，失败时不会写入任何元素
+func WriteValue(rb *ringbuffer.RingBuffer, v Value) error {
+	if err := rb.Grow(encodedLength(v)); err != nil {
+		return err
+	}
+
+	writeValue(rb, v)
+	return nil
+}
+
+// writeValue 写入 v，调用者需要已经预留了足够的空间
+func writeValue(rb *ringbuffer.RingBuffer, v Value) {
+	switch {
+	case v.IsNull && v.Type == Null:
+		_ = WriteNull(rb)
+	case v.IsNull:
+		_ = writeInt(rb, v.Type, -1)
+	case v.Type == Integer:
+		_ = WriteInteger(rb, v.Int)
+	case v.Type == Boolean:
+		_ = WriteBoolean(rb, v.Int != 0)
+	case v.Type.bulk():
+		_ = writeInt(rb, v.Type, int64(len(v.Str)))
+		_, _ = rb.Write(v.Str)
+		_, _ = rb.Write(crlf)
+	case v.Type.aggregate():
+		_ = writeInt(rb, v.Type, int64(aggregateLength(v)))
+		for _, e := range v.Elems {
+			writeValue(rb, e)
+		}
+	default:
+		_ = rb.WriteByte(byte(v.Type))
+		_, _ = rb.Write(v.Str)
+		_, _ = rb.Write(crlf)
+	}
+}
+
+// encodedLength 返回 writeValue 写入 v 的字节数
+func encodedLength(v Value) int {
+	switch {
+	case v.IsNull && v.Type == Null:
+		return 1 + len(crlf)
+	case v.IsNull:
+		return intLength(-1)
+	case v.Type == Integer:
+		return intLength(v.Int)
+	case v.Type == Boolean:
+		return 2 + len(crlf)
+	case v.Type.bulk():
+		return intLength(int64(len(v.Str))) + len(v.Str) + len(crlf)
+	case v.Type.aggregate():
+		n := intLength(int64(aggregateLength(v)))
+		for _, e := range v.Elems {
+			n += encodedLength(e)
+		}
+		return n
+	default:
+		return 1 + len(v.Str) + len(crlf)
+	}
+}
+
+// aggregateLength 返回聚合类型头部中的元素个数，Map 和 Attribute 为键值对的个数
+func aggregateLength(v Value) int {
+	if v.Type == Map || v.Type == Attribute {
+		return len(v.Elems) / 2
+	}
+	return len(v.Elems)
+}
+
+func writeLine(rb *ringbuffer.RingBuffer, t Type, s string) error {
+	if err := rb.Grow(1 + len(s) + len(crlf)); err != nil {
+		return err
+	}
+
+	_ = rb.WriteByte(byte(t))
+	_, _ = rb.WriteString(s)
+	_, _ = rb.Write(crlf)
+	return nil
+}
+
+func writeInt(rb *ringbuffer.RingBuffer, t Type, n int64) error {
+	var tmp [24]byte
+	_, err := rb.Write(appendInt(tmp[:0], t, n))
+	return err
+}
+
+// appendInt 将 "<t>n\r\n" 追加到 b
+func appendInt(b []byte, t Type, n int64) []byte {
+	b = append(b, byte(t))
+	b = strconv.AppendInt(b, n, 10)
+	return append(b, '\r', '\n')
+}
+
+// intLength 返回 appendInt 追加的字节数
+func intLength(n int64) int {
+	var tmp [24]byte
+	return len(strconv.AppendInt(tmp[:0], n, 10)) + 1 + len(crlf)
+}
@@ -0,0 +1,91 @@
+package resp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+func TestWrite(t *testing.T) {
+	rb := ringbuffer.New(4)
+
+	WriteArrayHeader(rb, 2)
+	WriteBulkStringString(rb, "GET")
+	WriteBulkString(rb, []byte("key"))
+	WriteSimpleString(rb, "OK")
+	WriteError(rb, "ERR x")
+	WriteInteger(rb, 100)
+	WriteNullBulkString(rb)
+	WriteNullArray(rb)
+	WriteMapHeader(rb, 1)
+	WriteBoolean(rb, false)
+	WriteDouble(rb, 1.5)
+	WriteSetHeader(rb, 1)
+	WriteNull(rb)
+
+	expect := "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n+OK\r\n-ERR x\r\n:100\r\n$-1\r\n*-1\r\n%1\r\n#f\r\n,1.5\r\n~1\r\n_\r\n"
+	if string(rb.Bytes()) != expect {
+		t.Fatalf("expect %q but got %q", expect, rb.Bytes())
+	}
+}
+
+func TestWriteValue(t *testing.T) {
+	data := "*4\r\n$1\r\na\r\n:1\r\n%1\r\n+k\r\n#t\r\n*-1\r\n"
+	rb := ringbuffer.New(64)
+	_, _ = rb.WriteString(data)
+
+	v, err := Decode(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	WriteValue(rb, v)
+	if string(rb.Bytes()) != data {
+		t.Fatalf("expect %q but got %q", data, rb.Bytes())
+	}
+}
+
+func TestWriteBudgetExceeded(t *testing.T) {
+	rb, err := ringbuffer.NewWithBudget(8, ringbuffer.NewBudget(16, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = WriteBulkStringString(rb, "0123456789abcdefghij"); err != ringbuffer.ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect nothing written but got %q", rb.Bytes())
+	}
+
+	v := Value{Type: Array, Elems: []Value{
+		{Type: BulkString, Str: []byte("SET")},
+		{Type: BulkString, Str: []byte("key")},
+	}}
+	if err = WriteValue(rb, v); err != ringbuffer.ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect nothing written but got %q", rb.Bytes())
+	}
+
+	if err = WriteSimpleString(rb, "OK"); err != nil {
+		t.Fatal(err)
+	}
+	if string(rb.Bytes()) != "+OK\r\n" {
+		t.Fatalf("expect +OK but got %q", rb.Bytes())
+	}
+}
+
+func TestWriteDoubleSpecial(t *testing.T) {
+	rb := ringbuffer.New(32)
+	_ = WriteDouble(rb, math.Inf(1))
+	_ = WriteDouble(rb, math.Inf(-1))
+	_ = WriteDouble(rb, math.NaN())
+	_ = WriteDouble(rb, -0.25)
+
+	expect := ",inf\r\n,-inf\r\n,nan\r\n,-0.25\r\n"
+	if string(rb.Bytes()) != expect {
+		t.Fatalf("expect %q but got %q", expect, rb.Bytes())
+	}
+}
@@ -0,0 +1,74 @@
+// Package resp 基于 RingBuffer 的 Redis RESP2/RESP3 协议编解码
+package resp
+
+import "errors"
+
+var (
+	// ErrNeedMore 数据不完整，需要等待更多数据
+	ErrNeedMore = errors.New("resp: need more data")
+	// ErrProtocol 协议错误
+	ErrProtocol = errors.New("resp: protocol error")
+)
+
+const (
+	// MaxBulkLength bulk string 的最大长度，同 Redis 的 proto-max-bulk-len 默认值
+	MaxBulkLength = 512 << 20
+	// MaxDepth 嵌套聚合类型的最大深度
+	MaxDepth = 64
+)
+
+// Type 数据类型，取值为协议中的类型前缀
+type Type byte
+
+// RESP2 类型
+const (
+	SimpleString Type = '+'
+	Error        Type = '-'
+	Integer      Type = ':'
+	BulkString   Type = '$'
+	Array        Type = '*'
+)
+
+// RESP3 类型
+const (
+	Null           Type = '_'
+	Boolean        Type = '#'
+	Double         Type = ','
+	BigNumber      Type = '('
+	BulkError      Type = '!'
+	VerbatimString Type = '='
+	Map            Type = '%'
+	Set            Type = '~'
+	Attribute      Type = '|'
+	Push           Type = '>'
+)
+
+// Value 一个 RESP 数据
+type Value struct {
+	Type Type
+	// Str SimpleString、Error、BulkString、BulkError、VerbatimString 的内容，
+	// Double 和 BigNumber 的文本形式
+	Str []byte
+	// Int Integer 的值，Boolean 为 true 时为 1
+	Int int64
+	// Elems Array、Set、Push 的元素；Map、Attribute 为 key、value 交替排列
+	Elems []Value
+	// IsNull RESP2 的 null bulk string（"$-1"）、null array（"*-1"）以及 RESP3 的 Null
+	IsNull bool
+}
+
+func (t Type) aggregate() bool {
+	switch t {
+	case Array, Map, Set, Attribute, Push:
+		return true
+	}
+	return false
+}
+
+func (t Type) bulk() bool {
+	switch t {
+	case BulkString, BulkError, VerbatimString:
+		return true
+	}
+	return false
+}
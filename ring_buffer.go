@@ -4,12 +4,16 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"unsafe"
 )
 
 // ErrIsEmpty 缓冲区为空
 var ErrIsEmpty = errors.New("ring buffer is empty")
 
+// ErrNegativeOffset 偏移量为负数
+var ErrNegativeOffset = errors.New("ring buffer: negative offset")
+
 // RingBuffer 自动扩容循环缓冲区
 type RingBuffer struct {
 	buf      []byte
@@ -19,6 +23,21 @@ type RingBuffer struct {
 	r        int // next position to read
 	w        int // next position to write
 	isEmpty  bool
+	vEmpty   bool   // 虚读后是否没有剩余数据，vr == w 时用于区分读完和写满
+	lastRead readOp // last read operation, so that Unread* can work correctly
+
+	refs int32 // 引用计数，见 Retain 和 Release
+	pool *Pool // 从 Pool 中获取时记录来源，引用计数归零时放回
+
+	budget    *Budget   // 关联的内存预算
+	alloc     Allocator // 底层数组的分配器，为 nil 时使用 make
+	finalizer bool      // 是否已经设置了 finalizer
+	pooled    bool      // 在 Pool 中闲置，被 GC 回收时由 finalizer 归还底层数组
+
+	shrink *shrinkState   // 自动缩容策略，为 nil 时不自动缩容
+	growth GrowthStrategy // 扩容策略，为 nil 时使用 AppendGrowth
+
+	debug debugState // ringbuffer_debug 构建标签下的 Get/Put 调用栈
 }
 
 // New 返回一个初始大小为 size 的 RingBuffer
@@ -28,6 +47,8 @@ func New(size int) *RingBuffer {
 		initSize: size,
 		size:     size,
 		isEmpty:  true,
+		vEmpty:   true,
+		refs:     1,
 	}
 }
 
@@ -37,14 +58,26 @@ func NewWithData(data []byte) *RingBuffer {
 		buf:      data,
 		size:     len(data),
 		initSize: len(data),
+		refs:     1,
 	}
 }
 
 func (r *RingBuffer) WithData(data []byte) {
+	r.checkUse()
 	r.r = 0
 	r.w = 0
 	r.vr = 0
 	r.isEmpty = false
+	r.vEmpty = false
+	r.lastRead = opInvalid
+	if r.budget != nil {
+		// data 由调用者申请，无法拒绝，直接计入预算
+		r.budget.Release(int64(len(r.buf)))
+		r.budget.add(int64(len(data)))
+	}
+	// data 不是由 Allocator 分配的，之后不能交给 Allocator 释放
+	r.freeBuf(r.buf)
+	r.alloc = nil
 	r.size = len(data)
 	r.initSize = len(data)
 	r.buf = data
@@ -53,42 +86,35 @@ func (r *RingBuffer) WithData(data []byte) {
 // VirtualFlush 刷新虚读指针
 // VirtualXXX 系列配合使用
 func (r *RingBuffer) VirtualFlush() {
+	r.checkUse()
+	r.lastRead = opInvalid
+	r.isEmpty = r.vEmpty
 	r.r = r.vr
-	if r.r == r.w {
-		r.isEmpty = true
-	}
+	r.trackShrink()
 }
 
 // VirtualRevert 还原虚读指针
 // VirtualXXX 系列配合使用
 func (r *RingBuffer) VirtualRevert() {
+	r.checkUse()
 	r.vr = r.r
+	r.vEmpty = r.isEmpty
 }
 
 // VirtualRead 虚读，不移动 read 指针，需要配合 VirtualFlush 和 VirtualRevert 使用
 // VirtualXXX 系列配合使用
 func (r *RingBuffer) VirtualRead(p []byte) (n int, err error) {
+	r.checkUse()
 	if len(p) == 0 {
 		return 0, nil
 	}
-	if r.isEmpty {
+	vlen := r.VirtualLength()
+	if vlen == 0 {
 		return 0, ErrIsEmpty
 	}
 	n = len(p)
-	if r.w > r.vr {
-		if n > r.w-r.vr {
-			n = r.w - r.vr
-		}
-		copy(p, r.buf[r.vr:r.vr+n])
-		// move vr
-		r.vr = (r.vr + n) % r.size
-		if r.vr == r.w {
-			r.isEmpty = true
-		}
-		return
-	}
-	if n > r.size-r.vr+r.w {
-		n = r.size - r.vr + r.w
+	if n > vlen {
+		n = vlen
 	}
 	if r.vr+n <= r.size {
 		copy(p, r.buf[r.vr:r.vr+n])
@@ -101,14 +127,18 @@ func (r *RingBuffer) VirtualRead(p []byte) (n int, err error) {
 
 	// move vr
 	r.vr = (r.vr + n) % r.size
+	if r.vr == r.w {
+		r.vEmpty = true
+	}
 	return
 }
 
 // VirtualLength 虚拟长度，虚读后剩余可读数据长度
 // VirtualXXX 系列配合使用
 func (r *RingBuffer) VirtualLength() int {
+	r.checkUse()
 	if r.w == r.vr {
-		if r.isEmpty {
+		if r.vEmpty {
 			return 0
 		}
 		return r.size
@@ -122,16 +152,22 @@ func (r *RingBuffer) VirtualLength() int {
 }
 
 func (r *RingBuffer) RetrieveAll() {
+	r.checkUse()
 	r.r = 0
 	r.w = 0
 	r.vr = 0
 	r.isEmpty = true
+	r.vEmpty = true
+	r.lastRead = opInvalid
+	r.trackShrink()
 }
 
 func (r *RingBuffer) Retrieve(len int) {
+	r.checkUse()
 	if r.isEmpty || len <= 0 {
 		return
 	}
+	r.lastRead = opInvalid
 
 	if len < r.Length() {
 		r.r = (r.r + len) % r.size
@@ -140,12 +176,15 @@ func (r *RingBuffer) Retrieve(len int) {
 		if r.w == r.r {
 			r.isEmpty = true
 		}
+		r.vEmpty = r.isEmpty
+		r.trackShrink()
 	} else {
 		r.RetrieveAll()
 	}
 }
 
 func (r *RingBuffer) Peek(len int) (first []byte, end []byte) {
+	r.checkUse()
 	if r.isEmpty || len <= 0 {
 		return
 	}
@@ -174,6 +213,7 @@ func (r *RingBuffer) Peek(len int) (first []byte, end []byte) {
 }
 
 func (r *RingBuffer) PeekAll() (first []byte, end []byte) {
+	r.checkUse()
 	if r.isEmpty {
 		return
 	}
@@ -189,24 +229,72 @@ func (r *RingBuffer) PeekAll() (first []byte, end []byte) {
 }
 
 func (r *RingBuffer) PeekUint8() uint8 {
-	if r.Length() < 1 {
-		return 0
+	r.checkUse()
+	return r.PeekUint8At(0)
+}
+
+func (r *RingBuffer) PeekUint16() uint16 {
+	r.checkUse()
+	return r.PeekUint16At(0)
+}
+
+func (r *RingBuffer) PeekUint32() uint32 {
+	r.checkUse()
+	return r.PeekUint32At(0)
+}
+
+func (r *RingBuffer) PeekUint64() uint64 {
+	r.checkUse()
+	return r.PeekUint64At(0)
+}
+
+// PeekAt 从读指针偏移 offset 处开始查看 n 个字节，不移动读指针
+// 可读数据不足时返回剩余部分
+func (r *RingBuffer) PeekAt(offset, n int) (first []byte, end []byte) {
+	r.checkUse()
+	if r.isEmpty || offset < 0 || n <= 0 {
+		return
 	}
 
-	f, e := r.Peek(1)
-	if len(e) > 0 {
-		return e[0]
+	length := r.Length()
+	if offset >= length {
+		return
+	}
+	if n > length-offset {
+		n = length - offset
+	}
+
+	start := (r.r + offset) % r.size
+	if start+n <= r.size {
+		first = r.buf[start : start+n]
 	} else {
-		return f[0]
+		// head
+		first = r.buf[start:r.size]
+		// tail
+		end = r.buf[0 : n-r.size+start]
 	}
+	return
 }
 
-func (r *RingBuffer) PeekUint16() uint16 {
-	if r.Length() < 2 {
+// PeekUint8At 查看偏移 offset 处的 uint8，可读数据不足时返回 0
+func (r *RingBuffer) PeekUint8At(offset int) uint8 {
+	r.checkUse()
+	if offset < 0 || r.Length()-offset < 1 {
 		return 0
 	}
 
-	f, e := r.Peek(2)
+	f, _ := r.PeekAt(offset, 1)
+	return f[0]
+}
+
+// PeekUint16At 查看偏移 offset 处的 uint16（大端序），可读数据不足时返回 0
+func (r *RingBuffer) PeekUint16At(offset int) uint16 {
+	r.checkUse()
+	if offset < 0 || r.Length()-offset < 2 {
+		return 0
+	}
+
+	f, e := r.PeekAt(offset, 2)
 	if len(e) > 0 {
 		return binary.BigEndian.Uint16(copyByte(f, e))
 	} else {
@@ -214,12 +302,14 @@ func (r *RingBuffer) PeekUint16() uint16 {
 	}
 }
 
-func (r *RingBuffer) PeekUint32() uint32 {
-	if r.Length() < 4 {
+// PeekUint32At 查看偏移 offset 处的 uint32（大端序），可读数据不足时返回 0
+func (r *RingBuffer) PeekUint32At(offset int) uint32 {
+	r.checkUse()
+	if offset < 0 || r.Length()-offset < 4 {
 		return 0
 	}
 
-	f, e := r.Peek(4)
+	f, e := r.PeekAt(offset, 4)
 	if len(e) > 0 {
 		return binary.BigEndian.Uint32(copyByte(f, e))
 	} else {
@@ -227,12 +317,14 @@ func (r *RingBuffer) PeekUint32() uint32 {
 	}
 }
 
-func (r *RingBuffer) PeekUint64() uint64 {
-	if r.Length() < 8 {
+// PeekUint64At 查看偏移 offset 处的 uint64（大端序），可读数据不足时返回 0
+func (r *RingBuffer) PeekUint64At(offset int) uint64 {
+	r.checkUse()
+	if offset < 0 || r.Length()-offset < 8 {
 		return 0
 	}
 
-	f, e := r.Peek(8)
+	f, e := r.PeekAt(offset, 8)
 	if len(e) > 0 {
 		return binary.BigEndian.Uint64(copyByte(f, e))
 	} else {
@@ -241,6 +333,7 @@ func (r *RingBuffer) PeekUint64() uint64 {
 }
 
 func (r *RingBuffer) Read(p []byte) (n int, err error) {
+	r.checkUse()
 	if len(p) == 0 {
 		return 0, nil
 	}
@@ -259,6 +352,9 @@ func (r *RingBuffer) Read(p []byte) (n int, err error) {
 			r.isEmpty = true
 		}
 		r.vr = r.r
+		r.vEmpty = r.isEmpty
+		r.lastRead = opRead
+		r.trackShrink()
 		return
 	}
 	if n > r.size-r.r+r.w {
@@ -279,10 +375,36 @@ func (r *RingBuffer) Read(p []byte) (n int, err error) {
 		r.isEmpty = true
 	}
 	r.vr = r.r
+	r.vEmpty = r.isEmpty
+	r.lastRead = opRead
+	r.trackShrink()
+	return
+}
+
+// ReadAt 实现 io.ReaderAt，将可读数据视为一个文件，从偏移 off 处读取，不移动读指针
+func (r *RingBuffer) ReadAt(p []byte, off int64) (n int, err error) {
+	r.checkUse()
+	if off < 0 {
+		return 0, ErrNegativeOffset
+	}
+	if off >= int64(r.Length()) {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+
+	first, end := r.PeekAt(int(off), len(p))
+	n = copy(p, first)
+	n += copy(p[n:], end)
+	if n < len(p) {
+		err = io.EOF
+	}
 	return
 }
 
 func (r *RingBuffer) ReadByte() (b byte, err error) {
+	r.checkUse()
 	if r.isEmpty {
 		return 0, ErrIsEmpty
 	}
@@ -296,17 +418,23 @@ func (r *RingBuffer) ReadByte() (b byte, err error) {
 		r.isEmpty = true
 	}
 	r.vr = r.r
+	r.vEmpty = r.isEmpty
+	r.lastRead = opRead
 	return
 }
 
 func (r *RingBuffer) Write(p []byte) (n int, err error) {
+	r.checkUse()
 	if len(p) == 0 {
 		return 0, nil
 	}
 	n = len(p)
+	r.maybeShrink(n)
 	free := r.free()
 	if free < n {
-		r.makeSpace(n - free)
+		if err = r.makeSpace(n - free); err != nil {
+			return 0, err
+		}
 	}
 	if r.w >= r.r {
 		if r.size-r.w >= n {
@@ -327,13 +455,19 @@ func (r *RingBuffer) Write(p []byte) (n int, err error) {
 	}
 
 	r.isEmpty = false
+	r.vEmpty = false
+	r.lastRead = opInvalid
 
 	return
 }
 
 func (r *RingBuffer) WriteByte(c byte) error {
+	r.checkUse()
+	r.maybeShrink(1)
 	if r.free() < 1 {
-		r.makeSpace(1)
+		if err := r.makeSpace(1); err != nil {
+			return err
+		}
 	}
 
 	r.buf[r.w] = c
@@ -344,11 +478,14 @@ func (r *RingBuffer) WriteByte(c byte) error {
 	}
 
 	r.isEmpty = false
+	r.vEmpty = false
+	r.lastRead = opInvalid
 
 	return nil
 }
 
 func (r *RingBuffer) Length() int {
+	r.checkUse()
 	if r.w == r.r {
 		if r.isEmpty {
 			return 0
@@ -364,10 +501,12 @@ func (r *RingBuffer) Length() int {
 }
 
 func (r *RingBuffer) Capacity() int {
+	r.checkUse()
 	return r.size
 }
 
 func (r *RingBuffer) WriteString(s string) (n int, err error) {
+	r.checkUse()
 	x := (*[2]uintptr)(unsafe.Pointer(&s))
 	h := [3]uintptr{x[0], x[1], x[1]}
 	return r.Write(*(*[]byte)(unsafe.Pointer(&h)))
@@ -375,6 +514,7 @@ func (r *RingBuffer) WriteString(s string) (n int, err error) {
 
 // Bytes 返回所有可读数据，此操作不会移动读指针，仅仅是拷贝全部数据
 func (r *RingBuffer) Bytes() (buf []byte) {
+	r.checkUse()
 	if r.isEmpty {
 		return
 	}
@@ -392,40 +532,85 @@ func (r *RingBuffer) Bytes() (buf []byte) {
 }
 
 func (r *RingBuffer) IsFull() bool {
+	r.checkUse()
 	return !r.isEmpty && r.w == r.r
 }
 
 func (r *RingBuffer) IsEmpty() bool {
+	r.checkUse()
 	return r.isEmpty
 }
 
 func (r *RingBuffer) Reset() {
+	r.checkUse()
 	r.r = 0
 	r.vr = 0
 	r.w = 0
 	r.isEmpty = true
+	r.vEmpty = true
+	r.lastRead = opInvalid
 	if r.size > r.initSize {
-		r.buf = make([]byte, r.initSize)
+		if r.budget != nil {
+			r.budget.Release(int64(r.size - r.initSize))
+		}
+		r.freeBuf(r.buf)
+		r.buf = r.allocBuf(r.initSize)
 		r.size = r.initSize
 	}
 }
 
 func (r *RingBuffer) String() string {
+	r.checkUse()
 	return fmt.Sprintf("Ring Buffer: \n\tCap: %d\n\tReadable Bytes: %d\n\tWriteable Bytes: %d\n\tBuffer: %s\n", r.size, r.Length(), r.free(), r.buf)
 }
 
-func (r *RingBuffer) makeSpace(len int) {
+func (r *RingBuffer) makeSpace(len int) error {
+	r.cancelShrink()
+	return r.resize(r.grow(r.size + len))
+}
+
+// resize 将可读数据搬到大小为 newSize 的新底层数组中，保留虚读偏移
+func (r *RingBuffer) resize(newSize int) error {
+	if r.budget != nil {
+		if newSize > r.size {
+			if err := r.budget.Reserve(int64(newSize - r.size)); err != nil {
+				return err
+			}
+		} else {
+			r.budget.Release(int64(r.size - newSize))
+		}
+	}
+
 	vlen := r.VirtualLength()
-	newSize := r.grow(r.size + len)
-	newBuf := make([]byte, newSize)
 	oldLen := r.Length()
-	_, _ = r.Read(newBuf)
+	newBuf := r.allocBuf(newSize)
+	first, end := r.PeekAll()
+	copy(newBuf, first)
+	copy(newBuf[len(first):], end)
 
-	r.w = oldLen
-	r.r = 0
-	r.vr = oldLen - vlen
+	r.freeBuf(r.buf)
+	r.w, r.r, r.vr = 0, 0, 0
+	if newSize > 0 {
+		r.w = oldLen % newSize
+		r.vr = (oldLen - vlen) % newSize
+	}
 	r.size = newSize
 	r.buf = newBuf
+	r.lastRead = opInvalid
+	return nil
+}
+
+func (r *RingBuffer) allocBuf(n int) []byte {
+	if r.alloc == nil {
+		return make([]byte, n)
+	}
+	return r.alloc.Alloc(n)
+}
+
+func (r *RingBuffer) freeBuf(b []byte) {
+	if r.alloc != nil && b != nil {
+		r.alloc.Free(b)
+	}
 }
 
 func (r *RingBuffer) free() int {
@@ -450,21 +635,11 @@ func copyByte(f, e []byte) []byte {
 }
 
 func (r *RingBuffer) grow(cap int) int {
-	newcap := r.size
-	doublecap := newcap + newcap
-	if cap > doublecap {
-		newcap = cap
-	} else {
-		if r.size < 1024 {
-			newcap = doublecap
-		} else {
-			for 0 < newcap && newcap < cap {
-				newcap += newcap / 4
-			}
-			if newcap <= 0 {
-				newcap = cap
-			}
-		}
+	if r.growth == nil {
+		return AppendGrowth{}.Grow(r.size, cap)
+	}
+	if newcap := r.growth.Grow(r.size, cap); newcap >= cap {
+		return newcap
 	}
-	return newcap
+	return cap
 }
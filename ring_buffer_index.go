@@ -0,0 +1,79 @@
+package ringbuffer
+
+import "bytes"
+
+// IndexByte 返回可读数据中第一个 c 相对读指针的偏移，不存在返回 -1
+func (r *RingBuffer) IndexByte(c byte) int {
+	r.checkUse()
+	first, end := r.PeekAll()
+	if i := bytes.IndexByte(first, c); i >= 0 {
+		return i
+	}
+	if i := bytes.IndexByte(end, c); i >= 0 {
+		return len(first) + i
+	}
+	return -1
+}
+
+// LastIndexByte 返回可读数据中最后一个 c 相对读指针的偏移，不存在返回 -1
+func (r *RingBuffer) LastIndexByte(c byte) int {
+	r.checkUse()
+	first, end := r.PeekAll()
+	if i := bytes.LastIndexByte(end, c); i >= 0 {
+		return len(first) + i
+	}
+	return bytes.LastIndexByte(first, c)
+}
+
+// Index 返回可读数据中第一个 sep 相对读指针的偏移，不存在返回 -1
+// 可以匹配到跨越环尾的 sep
+func (r *RingBuffer) Index(sep []byte) int {
+	r.checkUse()
+	return r.index(0, sep)
+}
+
+// index 从偏移 offset 处开始查找 sep
+func (r *RingBuffer) index(offset int, sep []byte) int {
+	first, end := r.PeekAt(offset, r.Length()-offset)
+	return indexSegments(first, end, sep, offset)
+}
+
+func indexSegments(first, end, sep []byte, offset int) int {
+	if len(sep) == 0 {
+		return offset
+	}
+	if len(sep) == 1 {
+		if i := bytes.IndexByte(first, sep[0]); i >= 0 {
+			return offset + i
+		}
+		if i := bytes.IndexByte(end, sep[0]); i >= 0 {
+			return offset + len(first) + i
+		}
+		return -1
+	}
+
+	if i := bytes.Index(first, sep); i >= 0 {
+		return offset + i
+	}
+	if len(end) == 0 {
+		return -1
+	}
+
+	// 跨越环尾的部分：first 末尾 len(sep)-1 字节 + end 开头 len(sep)-1 字节
+	head := len(first) - (len(sep) - 1)
+	if head < 0 {
+		head = 0
+	}
+	tail := len(sep) - 1
+	if tail > len(end) {
+		tail = len(end)
+	}
+	if i := bytes.Index(copyByte(first[head:], end[:tail]), sep); i >= 0 {
+		return offset + head + i
+	}
+
+	if i := bytes.Index(end, sep); i >= 0 {
+		return offset + len(first) + i
+	}
+	return -1
+}
@@ -0,0 +1,62 @@
+package ringbuffer
+
+import (
+	"strings"
+	"testing"
+)
+
+// newWrapped 返回一个可读数据为 data 且跨越环尾的 RingBuffer
+func newWrapped(data string, head int) *RingBuffer {
+	rb := New(len(data))
+	_, _ = rb.Write(make([]byte, len(data)-head))
+	_, _ = rb.Read(make([]byte, len(data)-head))
+	_, _ = rb.Write([]byte(data))
+	return rb
+}
+
+func TestRingBuffer_IndexByte(t *testing.T) {
+	rb := newWrapped("abc\r\ndef\n", 4)
+	if first, end := rb.PeekAll(); len(first) != 4 || len(end) != 5 {
+		t.Fatalf("expect wrapped data but got %q %q", first, end)
+	}
+
+	if i := rb.IndexByte('\n'); i != 4 {
+		t.Fatalf("expect 4 but got %d", i)
+	}
+	if i := rb.IndexByte('a'); i != 0 {
+		t.Fatalf("expect 0 but got %d", i)
+	}
+	if i := rb.IndexByte('x'); i != -1 {
+		t.Fatalf("expect -1 but got %d", i)
+	}
+	if i := rb.LastIndexByte('\n'); i != 8 {
+		t.Fatalf("expect 8 but got %d", i)
+	}
+	if i := rb.LastIndexByte('c'); i != 2 {
+		t.Fatalf("expect 2 but got %d", i)
+	}
+	if i := rb.LastIndexByte('x'); i != -1 {
+		t.Fatalf("expect -1 but got %d", i)
+	}
+
+	if i := New(8).IndexByte('a'); i != -1 {
+		t.Fatalf("expect -1 but got %d", i)
+	}
+}
+
+func TestRingBuffer_Index(t *testing.T) {
+	data := "abc\r\ndef\r\nghi"
+	for head := 1; head < len(data); head++ {
+		rb := newWrapped(data, head)
+		if string(rb.Bytes()) != data {
+			t.Fatalf("expect %q but got %q", data, rb.Bytes())
+		}
+
+		for _, sep := range []string{"\r\n", "c\r\nd", "ghi", "abc", "i", "", "xyz", "hij"} {
+			expect := strings.Index(data, sep)
+			if i := rb.Index([]byte(sep)); i != expect {
+				t.Fatalf("head %d sep %q: expect %d but got %d", head, sep, expect, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ringbuffer
+
+import "errors"
+
+// ErrDelimNotFound 可读数据中没有找到分隔符
+var ErrDelimNotFound = errors.New("ring buffer: delimiter not found")
+
+// ReadBytes 读取直到 delim（包含 delim）的数据，并移动读指针
+// 没有找到 delim 时不移动读指针，返回 ErrDelimNotFound
+func (r *RingBuffer) ReadBytes(delim byte) (line []byte, err error) {
+	r.checkUse()
+	if r.isEmpty {
+		return nil, ErrIsEmpty
+	}
+
+	i := r.IndexByte(delim)
+	if i < 0 {
+		return nil, ErrDelimNotFound
+	}
+
+	line = make([]byte, i+1)
+	_, _ = r.Read(line)
+	return
+}
+
+// ReadString 同 ReadBytes，返回 string
+func (r *RingBuffer) ReadString(delim byte) (line string, err error) {
+	r.checkUse()
+	b, err := r.ReadBytes(delim)
+	return string(b), err
+}
+
+// ReadLine 读取一行数据，返回的数据不包含行尾的 "\n" 或 "\r\n"
+// 没有完整的一行时不移动读指针，返回 ErrDelimNotFound
+func (r *RingBuffer) ReadLine() (line []byte, err error) {
+	r.checkUse()
+	if r.isEmpty {
+		return nil, ErrIsEmpty
+	}
+
+	first, end, n := r.PeekLine()
+	if n == 0 {
+		return nil, ErrDelimNotFound
+	}
+
+	line = copyByte(first, end)
+	r.Retrieve(n)
+	return
+}
+
+// PeekLine 查看一行数据，不移动读指针
+// first 和 end 不包含行尾的 "\n" 或 "\r\n"，n 为包含行尾在内的长度，可直接用于 Retrieve
+// 没有完整的一行时 n 为 0
+func (r *RingBuffer) PeekLine() (first []byte, end []byte, n int) {
+	r.checkUse()
+	i := r.IndexByte('\n')
+	if i < 0 {
+		return
+	}
+
+	n = i + 1
+	if i > 0 && r.PeekUint8At(i-1) == '\r' {
+		i--
+	}
+	first, end = r.Peek(i)
+	return
+}
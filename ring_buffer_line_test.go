@@ -0,0 +1,73 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRingBuffer_ReadBytes(t *testing.T) {
+	rb := newWrapped("PING\r\nSET a 1\r\nGET", 3)
+
+	line, err := rb.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(line, []byte("PING\r\n")) {
+		t.Fatalf("expect PING\\r\\n but got %q", line)
+	}
+
+	s, err := rb.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "SET a 1\r\n" {
+		t.Fatalf("expect SET a 1\\r\\n but got %q", s)
+	}
+
+	_, err = rb.ReadBytes('\n')
+	if err != ErrDelimNotFound {
+		t.Fatalf("expect ErrDelimNotFound but got %v", err)
+	}
+	if rb.Length() != 3 {
+		t.Fatalf("expect len 3 bytes but got %d", rb.Length())
+	}
+
+	rb.RetrieveAll()
+	_, err = rb.ReadString('\n')
+	if err != ErrIsEmpty {
+		t.Fatalf("expect ErrIsEmpty but got %v", err)
+	}
+}
+
+func TestRingBuffer_ReadLine(t *testing.T) {
+	rb := newWrapped("Host: a\r\nAccept: b\n\r\nX", 12)
+
+	first, end, n := rb.PeekLine()
+	if n != 9 || !bytes.Equal(first, []byte("Host: a")) || len(end) != 0 {
+		t.Fatalf("expect Host: a but got %q %q %d", first, end, n)
+	}
+	if rb.Length() != 22 {
+		t.Fatalf("expect len 22 bytes but got %d", rb.Length())
+	}
+
+	for _, expect := range []string{"Host: a", "Accept: b", ""} {
+		line, err := rb.ReadLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != expect {
+			t.Fatalf("expect %q but got %q", expect, line)
+		}
+	}
+
+	_, err := rb.ReadLine()
+	if err != ErrDelimNotFound {
+		t.Fatalf("expect ErrDelimNotFound but got %v", err)
+	}
+	if _, _, n = rb.PeekLine(); n != 0 {
+		t.Fatalf("expect 0 but got %d", n)
+	}
+	if rb.Length() != 1 {
+		t.Fatalf("expect len 1 bytes but got %d", rb.Length())
+	}
+}
@@ -0,0 +1,69 @@
+package ringbuffer
+
+import "sync/atomic"
+
+// Retain 增加引用计数，用于多个持有者共享同一个 RingBuffer，例如将同一份数据写给多个连接
+// 每次 Retain 都需要对应一次 Release，对已经归零的 RingBuffer 调用会 panic
+func (r *RingBuffer) Retain() {
+	r.checkUse()
+	for {
+		refs := atomic.LoadInt32(&r.refs)
+		if refs <= 0 {
+			panic("ringbuffer: Retain called on released buffer")
+		}
+		if atomic.CompareAndSwapInt32(&r.refs, refs, refs+1) {
+			return
+		}
+	}
+}
+
+// Release 减少引用计数，计数归零时，从 Pool 中获取的 RingBuffer 会被放回原来的 Pool
+// 归零之后不能再使用该 RingBuffer，重复 Release 会 panic，
+// 使用 ringbuffer_debug 构建标签时会打印归零时 Put 或 Release 的调用栈
+func (r *RingBuffer) Release() {
+	r.checkUse()
+	for {
+		refs := atomic.LoadInt32(&r.refs)
+		if refs <= 0 {
+			panic("ringbuffer: Release called more times than Retain")
+		}
+		if !atomic.CompareAndSwapInt32(&r.refs, refs, refs-1) {
+			continue
+		}
+
+		if refs == 1 {
+			if r.pool != nil {
+				r.pool.Put(r)
+			} else {
+				debugTrackRelease(r)
+			}
+		}
+		return
+	}
+}
+
+// RefCount 返回当前的引用计数
+func (r *RingBuffer) RefCount() int32 {
+	r.checkUse()
+	return atomic.LoadInt32(&r.refs)
+}
+
+// attach 记录来源 Pool 并重置引用计数
+func (r *RingBuffer) attach(p *Pool) {
+	r.pool = p
+	r.pooled = false
+	atomic.StoreInt32(&r.refs, 1)
+}
+
+// detach 在 Put 时将引用计数归零，仍被其他持有者 Retain 时 panic
+func (r *RingBuffer) detach() {
+	for {
+		refs := atomic.LoadInt32(&r.refs)
+		if refs > 1 {
+			panic("ringbuffer: Put called on buffer that is still retained")
+		}
+		if atomic.CompareAndSwapInt32(&r.refs, refs, 0) {
+			return
+		}
+	}
+}
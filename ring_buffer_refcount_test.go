@@ -0,0 +1,101 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRingBuffer_RefCount(t *testing.T) {
+	p := NewPool(PoolOptions{})
+	b := p.Get()
+	_, _ = b.WriteString("broadcast")
+	if b.RefCount() != 1 {
+		t.Fatalf("expect 1 but got %d", b.RefCount())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		b.Retain()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			first, end := b.PeekAll()
+			if string(first)+string(end) != "broadcast" {
+				t.Errorf("unexpected data %q %q", first, end)
+			}
+			b.Release()
+		}()
+	}
+	wg.Wait()
+
+	if b.RefCount() != 1 {
+		t.Fatalf("expect 1 but got %d", b.RefCount())
+	}
+	if s := p.Stats(); s.Puts != 0 {
+		t.Fatalf("expect 0 puts but got %d", s.Puts)
+	}
+
+	b.Release()
+	if s := p.Stats(); s.Puts != 1 {
+		t.Fatalf("expect 1 put but got %d", s.Puts)
+	}
+}
+
+func TestRingBuffer_DoubleRelease(t *testing.T) {
+	b := New(8)
+	b.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expect panic")
+		}
+	}()
+	b.Release()
+}
+
+func TestRingBuffer_RetainAfterRelease(t *testing.T) {
+	b := New(8)
+	b.Release()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expect panic")
+			}
+		}()
+		b.Retain()
+	}()
+	if DebugEnabled {
+		return
+	}
+	if b.RefCount() != 0 {
+		t.Fatalf("expect 0 but got %d", b.RefCount())
+	}
+}
+
+func TestRingBuffer_PutRetained(t *testing.T) {
+	p := NewPool(PoolOptions{})
+	b := p.Get()
+	b.Retain()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expect panic")
+			}
+		}()
+		p.Put(b)
+	}()
+	if b.RefCount() != 2 {
+		t.Fatalf("expect 2 but got %d", b.RefCount())
+	}
+	if s := p.Stats(); s.Puts != 0 {
+		t.Fatalf("expect 0 puts but got %d", s.Puts)
+	}
+
+	b.Release()
+	b.Release()
+	if s := p.Stats(); s.Puts != 1 {
+		t.Fatalf("expect 1 put but got %d", s.Puts)
+	}
+}
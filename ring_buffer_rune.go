@@ -0,0 +1,91 @@
+package ringbuffer
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrUnreadByte UnreadByte 之前的操作不是读操作
+var ErrUnreadByte = errors.New("ring buffer: UnreadByte: previous operation was not a successful read")
+
+// ErrUnreadRune UnreadRune 之前的操作不是 ReadRune
+var ErrUnreadRune = errors.New("ring buffer: UnreadRune: previous operation was not a successful ReadRune")
+
+// readOp 记录上一次读操作，参考 bytes.Buffer
+type readOp int8
+
+const (
+	opRead      readOp = -1 // Any other read operation.
+	opInvalid   readOp = 0  // Non-read operation.
+	opReadRune1 readOp = 1  // Read rune of size 1.
+	opReadRune2 readOp = 2  // Read rune of size 2.
+	opReadRune3 readOp = 3  // Read rune of size 3.
+	opReadRune4 readOp = 4  // Read rune of size 4.
+)
+
+// ReadRune 读取一个 UTF-8 编码的字符，支持跨越环尾的字符
+// 缓冲区为空或者剩余数据不足一个完整字符时返回 io.EOF，且不移动读指针，以便兼容 fmt.Fscan 等
+func (r *RingBuffer) ReadRune() (ch rune, size int, err error) {
+	r.checkUse()
+	if r.isEmpty {
+		return 0, 0, io.EOF
+	}
+
+	c := r.buf[r.r]
+	if c < utf8.RuneSelf {
+		_, _ = r.ReadByte()
+		r.lastRead = opReadRune1
+		return rune(c), 1, nil
+	}
+
+	var tmp [utf8.UTFMax]byte
+	first, end := r.Peek(utf8.UTFMax)
+	n := copy(tmp[:], first)
+	n += copy(tmp[n:], end)
+	if !utf8.FullRune(tmp[:n]) {
+		return 0, 0, io.EOF
+	}
+
+	ch, size = utf8.DecodeRune(tmp[:n])
+	// 不使用 Retrieve，它在读空时会重置读写指针，导致无法 UnreadRune
+	r.r = (r.r + size) % r.size
+	if r.r == r.w {
+		r.isEmpty = true
+	}
+	r.vr = r.r
+	r.vEmpty = r.isEmpty
+	r.lastRead = readOp(size)
+	return
+}
+
+// UnreadRune 回退上一次 ReadRune 读取的字符
+func (r *RingBuffer) UnreadRune() error {
+	r.checkUse()
+	if r.lastRead <= opInvalid {
+		return ErrUnreadRune
+	}
+
+	r.unread(int(r.lastRead))
+	return nil
+}
+
+// UnreadByte 回退上一次读操作读取的最后一个字节
+func (r *RingBuffer) UnreadByte() error {
+	r.checkUse()
+	if r.lastRead == opInvalid {
+		return ErrUnreadByte
+	}
+
+	r.unread(1)
+	return nil
+}
+
+// unread 将读指针回退 n 个字节，调用者需要保证这 n 个字节刚刚被读取且没有被覆盖
+func (r *RingBuffer) unread(n int) {
+	r.lastRead = opInvalid
+	r.r = (r.r - n + r.size) % r.size
+	r.vr = r.r
+	r.isEmpty = false
+	r.vEmpty = false
+}
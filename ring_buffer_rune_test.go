@@ -0,0 +1,122 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRingBuffer_ReadRune(t *testing.T) {
+	data := "a世界b"
+	for head := 1; head < len(data); head++ {
+		rb := newWrapped(data, head)
+
+		for _, expect := range data {
+			ch, size, err := rb.ReadRune()
+			if err != nil {
+				t.Fatalf("head %d: %v", head, err)
+			}
+			if ch != expect || size != utf8.RuneLen(expect) {
+				t.Fatalf("head %d: expect %q but got %q, size %d", head, expect, ch, size)
+			}
+		}
+
+		_, _, err := rb.ReadRune()
+		if err != io.EOF {
+			t.Fatalf("expect EOF but got %v", err)
+		}
+	}
+}
+
+func TestRingBuffer_ReadRuneIncomplete(t *testing.T) {
+	rb := New(8)
+	_, _ = rb.Write([]byte("世")[:2])
+
+	_, _, err := rb.ReadRune()
+	if err != io.EOF {
+		t.Fatalf("expect EOF but got %v", err)
+	}
+	if rb.Length() != 2 {
+		t.Fatalf("expect len 2 bytes but got %d", rb.Length())
+	}
+
+	_ = rb.WriteByte([]byte("世")[2])
+	ch, size, err := rb.ReadRune()
+	if err != nil || ch != '世' || size != 3 {
+		t.Fatalf("expect 世 but got %q, size %d, err %v", ch, size, err)
+	}
+}
+
+func TestRingBuffer_Unread(t *testing.T) {
+	rb := newWrapped("世a", 2)
+
+	if err := rb.UnreadByte(); err != ErrUnreadByte {
+		t.Fatalf("expect ErrUnreadByte but got %v", err)
+	}
+
+	ch, _, _ := rb.ReadRune()
+	if ch != '世' {
+		t.Fatalf("expect 世 but got %q", ch)
+	}
+	if err := rb.UnreadRune(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.UnreadRune(); err != ErrUnreadRune {
+		t.Fatalf("expect ErrUnreadRune but got %v", err)
+	}
+	if rb.Length() != 4 || !rb.IsFull() {
+		t.Fatalf("expect full but got len %d", rb.Length())
+	}
+
+	ch, _, _ = rb.ReadRune()
+	if ch != '世' {
+		t.Fatalf("expect 世 but got %q", ch)
+	}
+	b, _ := rb.ReadByte()
+	if b != 'a' || !rb.IsEmpty() {
+		t.Fatalf("expect a but got %q", b)
+	}
+	if err := rb.UnreadByte(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.UnreadRune(); err != ErrUnreadRune {
+		t.Fatalf("expect ErrUnreadRune but got %v", err)
+	}
+	if rb.Length() != 1 {
+		t.Fatalf("expect len 1 bytes but got %d", rb.Length())
+	}
+
+	rb = New(8)
+	_, _ = rb.WriteString("界")
+	_, _, _ = rb.ReadRune()
+	if err := rb.UnreadRune(); err != nil {
+		t.Fatal(err)
+	}
+	ch, _, _ = rb.ReadRune()
+	if ch != '界' {
+		t.Fatalf("expect 界 but got %q", ch)
+	}
+
+	_ = rb.WriteByte('b')
+	if err := rb.UnreadByte(); err != ErrUnreadByte {
+		t.Fatalf("expect ErrUnreadByte but got %v", err)
+	}
+}
+
+func TestRingBuffer_Fscan(t *testing.T) {
+	rb := newWrapped("42 世界 3.5", 4)
+
+	var (
+		i int
+		s string
+		f float64
+	)
+	n, err := fmt.Fscan(rb, &i, &s, &f)
+	if n != 3 || err != nil {
+		t.Fatalf("expect scan 3 items but got %d, err %v", n, err)
+	}
+	if i != 42 || s != "世界" || f != 3.5 {
+		t.Fatalf("unexpected %d %q %v", i, s, f)
+	}
+}
@@ -0,0 +1,43 @@
+package ringbuffer
+
+import "bufio"
+
+// Scan 使用 bufio.SplitFunc 切分可读数据，返回下一个 token，并按 split 返回的 advance 移动读指针
+// 数据不足一个 token 时返回 nil, nil，不移动读指针
+// 可读数据连续时 token 直接引用内部缓冲区，仅在下一次写入前有效；跨越环尾时才会拷贝
+func (r *RingBuffer) Scan(split bufio.SplitFunc) (token []byte, err error) {
+	r.checkUse()
+	return r.scan(split, false)
+}
+
+// ScanAtEOF 同 Scan，但以 atEOF 为 true 调用 split，用于连接关闭后取出剩余的 token
+func (r *RingBuffer) ScanAtEOF(split bufio.SplitFunc) (token []byte, err error) {
+	r.checkUse()
+	return r.scan(split, true)
+}
+
+func (r *RingBuffer) scan(split bufio.SplitFunc, atEOF bool) (token []byte, err error) {
+	for {
+		first, end := r.PeekAll()
+		data := first
+		if len(end) > 0 {
+			data = copyByte(first, end)
+		}
+		if len(data) == 0 && !atEOF {
+			return nil, nil
+		}
+
+		advance, token, err := split(data, atEOF)
+		if advance < 0 {
+			return nil, bufio.ErrNegativeAdvance
+		}
+		if advance > len(data) {
+			return nil, bufio.ErrAdvanceTooFar
+		}
+		r.Retrieve(advance)
+
+		if err != nil || token != nil || advance == 0 {
+			return token, err
+		}
+	}
+}
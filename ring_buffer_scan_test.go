@@ -0,0 +1,66 @@
+package ringbuffer
+
+import (
+	"bufio"
+	"testing"
+)
+
+func TestRingBuffer_Scan(t *testing.T) {
+	rb := newWrapped("  hello ring\tbuffer  wor", 8)
+
+	for _, expect := range []string{"hello", "ring", "buffer"} {
+		token, err := rb.Scan(bufio.ScanWords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(token) != expect {
+			t.Fatalf("expect %q but got %q", expect, token)
+		}
+	}
+
+	token, err := rb.Scan(bufio.ScanWords)
+	if token != nil || err != nil {
+		t.Fatalf("expect nil token but got %q, err %v", token, err)
+	}
+	// ScanWords 会跳过前导空白
+	if rb.Length() != 3 {
+		t.Fatalf("expect len 3 bytes but got %d", rb.Length())
+	}
+
+	_, _ = rb.WriteString("ld\n")
+	token, err = rb.Scan(bufio.ScanLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(token) != "world" {
+		t.Fatalf("expect world but got %q", token)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect empty but got len %d", rb.Length())
+	}
+}
+
+func TestRingBuffer_ScanAtEOF(t *testing.T) {
+	rb := New(16)
+	_, _ = rb.WriteString("tail")
+
+	token, err := rb.Scan(bufio.ScanLines)
+	if token != nil || err != nil {
+		t.Fatalf("expect nil token but got %q, err %v", token, err)
+	}
+	token, err = rb.ScanAtEOF(bufio.ScanLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(token) != "tail" {
+		t.Fatalf("expect tail but got %q", token)
+	}
+
+	tooFar := func(data []byte, atEOF bool) (int, []byte, error) {
+		return len(data) + 1, nil, nil
+	}
+	_, _ = rb.WriteString("x")
+	if _, err = rb.Scan(tooFar); err != bufio.ErrAdvanceTooFar {
+		t.Fatalf("expect ErrAdvanceTooFar but got %v", err)
+	}
+}
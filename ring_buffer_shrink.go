@@ -0,0 +1,90 @@
+package ringbuffer
+
+// ShrinkPolicy 自动缩容策略
+//
+// 每次 Read、Retrieve、RetrieveAll、VirtualFlush 之后检查利用率（Length / Capacity），
+// 容量大于初始大小且利用率连续 Ops 次低于 Threshold 时，在下一次 Write 或 WriteByte 开始时缩容，
+// 读操作本身不会更换底层数组，之前通过 Peek 等方法得到的切片在下一次写入之前依然有效
+// Grow 以及扩容会取消待执行的缩容，Grow 预留的空间不会被缩容收回
+type ShrinkPolicy struct {
+	// Threshold 利用率阈值，取值 (0, 1)
+	Threshold float64
+	// Ops 连续低于阈值的次数，为 0 时按 1 处理
+	Ops int
+}
+
+type shrinkState struct {
+	policy  ShrinkPolicy
+	lowOps  int
+	pending bool // 下一次写入时缩容
+}
+
+// SetShrinkPolicy 设置自动缩容策略，policy.Threshold 不大于 0 时关闭自动缩容
+func (r *RingBuffer) SetShrinkPolicy(policy ShrinkPolicy) {
+	r.checkUse()
+	if policy.Threshold <= 0 {
+		r.shrink = nil
+		return
+	}
+	if policy.Ops <= 0 {
+		policy.Ops = 1
+	}
+	r.shrink = &shrinkState{policy: policy}
+}
+
+// Shrink 缩容到 max(初始大小, 2 * Length())，保留可读数据和虚读偏移
+// 当前容量不大于目标大小时什么也不做
+func (r *RingBuffer) Shrink() {
+	r.checkUse()
+	r.shrinkFor(0)
+}
+
+// shrinkFor 缩容到 max(初始大小, 2 * (Length() + n))，n 为接下来要写入的字节数
+func (r *RingBuffer) shrinkFor(n int) {
+	target := 2 * (r.Length() + n)
+	if target < r.initSize {
+		target = r.initSize
+	}
+	if target >= r.size {
+		return
+	}
+
+	// 缩容只会归还预算，不会失败
+	_ = r.resize(target)
+}
+
+// trackShrink 在读操作之后检查利用率，达到条件时标记在下一次写入时缩容
+func (r *RingBuffer) trackShrink() {
+	s := r.shrink
+	if s == nil {
+		return
+	}
+	if r.size <= r.initSize || float64(r.Length()) >= float64(r.size)*s.policy.Threshold {
+		s.lowOps = 0
+		return
+	}
+
+	s.lowOps++
+	if s.lowOps >= s.policy.Ops {
+		s.lowOps = 0
+		s.pending = true
+	}
+}
+
+// maybeShrink 在写入 n 个字节之前执行 trackShrink 标记的缩容
+func (r *RingBuffer) maybeShrink(n int) {
+	s := r.shrink
+	if s == nil || !s.pending {
+		return
+	}
+
+	s.pending = false
+	r.shrinkFor(n)
+}
+
+// cancelShrink 取消 trackShrink 标记的缩容
+func (r *RingBuffer) cancelShrink() {
+	if r.shrink != nil {
+		r.shrink.pending = false
+	}
+}
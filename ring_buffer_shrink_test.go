@@ -0,0 +1,145 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRingBuffer_Shrink(t *testing.T) {
+	rb := New(8)
+	_, _ = rb.WriteString(strings.Repeat("a", 100))
+	_, _ = rb.Read(make([]byte, 97))
+	_, _ = rb.WriteString("bcdefgh")
+
+	// 可读数据 aaabcdefgh，跨越了环尾
+	if first, end := rb.PeekAll(); len(end) == 0 {
+		t.Fatalf("expect wrapped data but got %q %q", first, end)
+	}
+	_, _ = rb.VirtualRead(make([]byte, 3))
+
+	rb.Shrink()
+	if rb.Capacity() != 20 {
+		t.Fatalf("expect capacity 20 but got %d", rb.Capacity())
+	}
+	if !bytes.Equal(rb.Bytes(), []byte("aaabcdefgh")) {
+		t.Fatalf("expect aaabcdefgh but got %s", rb.Bytes())
+	}
+	if rb.VirtualLength() != 7 {
+		t.Fatalf("expect virtual len 7 but got %d", rb.VirtualLength())
+	}
+	buf := make([]byte, 7)
+	_, _ = rb.VirtualRead(buf)
+	if string(buf) != "bcdefgh" {
+		t.Fatalf("expect bcdefgh but got %s", buf)
+	}
+	rb.VirtualRevert()
+
+	rb.Retrieve(6)
+	rb.Shrink()
+	if rb.Capacity() != 8 || string(rb.Bytes()) != "efgh" {
+		t.Fatalf("unexpected cap %d, data %s", rb.Capacity(), rb.Bytes())
+	}
+	rb.Shrink()
+	if rb.Capacity() != 8 {
+		t.Fatalf("expect capacity 8 but got %d", rb.Capacity())
+	}
+
+	_, _ = rb.WriteString("1234")
+	if !rb.IsFull() || string(rb.Bytes()) != "efgh1234" {
+		t.Fatalf("unexpected data %s", rb.Bytes())
+	}
+}
+
+func TestRingBuffer_ShrinkPolicy(t *testing.T) {
+	budget := NewBudget(1<<20, false)
+	rb, _ := NewWithBudget(16, budget)
+	rb.SetShrinkPolicy(ShrinkPolicy{Threshold: 0.25, Ops: 3})
+
+	_, _ = rb.Write(make([]byte, 1000))
+	rb.Retrieve(960)
+	buf := make([]byte, 10)
+	_, _ = rb.Read(buf)
+	if rb.Capacity() != 1000 || rb.Length() != 30 {
+		t.Fatalf("unexpected cap %d len %d", rb.Capacity(), rb.Length())
+	}
+
+	// 连续第 3 次利用率低于 0.25，在下一次写入时缩容
+	_, _ = rb.Read(buf)
+	if rb.Capacity() != 1000 || rb.Length() != 20 {
+		t.Fatalf("unexpected cap %d len %d", rb.Capacity(), rb.Length())
+	}
+	_ = rb.WriteByte(1)
+	if rb.Capacity() != 42 || rb.Length() != 21 {
+		t.Fatalf("unexpected cap %d len %d", rb.Capacity(), rb.Length())
+	}
+	if s := budget.Stats(); s.Reserved != 42 {
+		t.Fatalf("expect reserved 42 but got %d", s.Reserved)
+	}
+
+	rb.RetrieveAll()
+	rb.RetrieveAll()
+	rb.RetrieveAll()
+	_, _ = rb.Write(buf[:1])
+	if rb.Capacity() != 16 {
+		t.Fatalf("expect capacity 16 but got %d", rb.Capacity())
+	}
+
+	rb.RetrieveAll()
+	rb.SetShrinkPolicy(ShrinkPolicy{})
+	_, _ = rb.Write(make([]byte, 1000))
+	for i := 0; i < 10; i++ {
+		rb.RetrieveAll()
+	}
+	_ = rb.WriteByte(1)
+	if rb.Capacity() != 1000 {
+		t.Fatalf("expect capacity 1000 but got %d", rb.Capacity())
+	}
+}
+
+func TestRingBuffer_ShrinkKeepsPeekedData(t *testing.T) {
+	a := NewSlabAllocator(64, 1024)
+	rb := NewWithAllocator(64, a)
+	rb.SetShrinkPolicy(ShrinkPolicy{Threshold: 0.5})
+
+	data := bytes.Repeat([]byte("x"), 200)
+	_, _ = rb.Write(data)
+	first, end := rb.Peek(200)
+	rb.Retrieve(200)
+
+	// 读操作不会缩容，底层数组不会被归还给 Allocator 并复用
+	other := NewWithAllocator(200, a)
+	_, _ = other.Write(make([]byte, 200))
+	if got := append(append([]byte{}, first...), end...); !bytes.Equal(got, data) {
+		t.Fatalf("peeked data was overwritten: %q", got)
+	}
+	if rb.Capacity() < 200 {
+		t.Fatalf("expect no shrink before write but got capacity %d", rb.Capacity())
+	}
+
+	_ = rb.WriteByte(1)
+	if rb.Capacity() != 64 {
+		t.Fatalf("expect capacity 64 but got %d", rb.Capacity())
+	}
+}
+
+func TestRingBuffer_ShrinkAfterGrow(t *testing.T) {
+	rb := New(16)
+	rb.SetShrinkPolicy(ShrinkPolicy{Threshold: 0.25})
+	_, _ = rb.Write(make([]byte, 4096))
+	rb.RetrieveAll()
+
+	// 缩容已经被标记，Grow 预留的空间在之后的写入中依然可用
+	if err := rb.Grow(2000); err != nil {
+		t.Fatal(err)
+	}
+	capacity := rb.Capacity()
+	_ = rb.WriteByte(1)
+	if rb.Capacity() != capacity || rb.Capacity()-rb.Length() < 1999 {
+		t.Fatalf("unexpected cap %d len %d", rb.Capacity(), rb.Length())
+	}
+	_, _ = rb.Write(make([]byte, 1999))
+	if rb.Capacity() != capacity {
+		t.Fatalf("expect capacity %d but got %d", capacity, rb.Capacity())
+	}
+}
@@ -15,6 +15,9 @@ func TestRingBuffer_interface(t *testing.T) {
 	var _ io.StringWriter = rb
 	var _ io.ByteReader = rb
 	var _ io.ByteWriter = rb
+	var _ io.ReaderAt = rb
+	var _ io.ByteScanner = rb
+	var _ io.RuneScanner = rb
 }
 
 func TestRingBuffer_Write(t *testing.T) {
@@ -584,6 +587,97 @@ func TestRingBuffer_VirtualXXX(t *testing.T) {
 
 }
 
+func TestRingBuffer_VirtualReadToEnd(t *testing.T) {
+	// 虚读跨越环尾直到读完
+	rb := New(8)
+	_, _ = rb.Write([]byte("123456"))
+	_, _ = rb.Read(make([]byte, 5))
+	_, _ = rb.Write([]byte("abcd"))
+	buf := make([]byte, 8)
+	n, err := rb.VirtualRead(buf)
+	if n != 5 || err != nil || !bytes.Equal(buf[:n], []byte("6abcd")) {
+		t.Fatalf("expect 6abcd but got %s, err %v", buf[:n], err)
+	}
+	if rb.VirtualLength() != 0 {
+		t.Fatal(rb.VirtualLength())
+	}
+	if _, err = rb.VirtualRead(buf); err != ErrIsEmpty {
+		t.Fatalf("expect ErrIsEmpty but got %v", err)
+	}
+	rb.VirtualRevert()
+	if rb.IsEmpty() || rb.VirtualLength() != 5 {
+		t.Fatal(rb.VirtualLength())
+	}
+	_, _ = rb.VirtualRead(buf)
+	rb.VirtualFlush()
+	if !rb.IsEmpty() || rb.Length() != 0 {
+		t.Fatal(rb.Length())
+	}
+
+	// 缓冲区满时 VirtualFlush 不应清空数据
+	rb = New(4)
+	_, _ = rb.Write([]byte("abcd"))
+	rb.VirtualFlush()
+	if !rb.IsFull() || rb.VirtualLength() != 4 {
+		t.Fatal(rb.Length())
+	}
+}
+
+func TestRingBuffer_VirtualLengthEmpty(t *testing.T) {
+	for _, rb := range []*RingBuffer{New(8), NewWithAllocator(8, HeapAllocator{})} {
+		if rb.VirtualLength() != 0 {
+			t.Fatalf("expect 0 but got %d", rb.VirtualLength())
+		}
+		if _, err := rb.VirtualRead(make([]byte, 8)); err != ErrIsEmpty {
+			t.Fatalf("expect ErrIsEmpty but got %v", err)
+		}
+	}
+}
+
+func TestRingBuffer_VirtualReadFull(t *testing.T) {
+	rb := New(7)
+	_, _ = rb.Write([]byte("1234567"))
+
+	buf := make([]byte, 7)
+	n, err := rb.VirtualRead(buf)
+	if n != 7 || err != nil || !bytes.Equal(buf, []byte("1234567")) {
+		t.Fatalf("expect 1234567 but got %s, err %v", buf[:n], err)
+	}
+	if rb.VirtualLength() != 0 || rb.Length() != 7 {
+		t.Fatal(rb.VirtualLength(), rb.Length())
+	}
+	if _, err = rb.VirtualRead(buf); err != ErrIsEmpty {
+		t.Fatalf("expect ErrIsEmpty but got %v", err)
+	}
+
+	rb.VirtualRevert()
+	if rb.VirtualLength() != 7 {
+		t.Fatal(rb.VirtualLength())
+	}
+
+	_, _ = rb.VirtualRead(buf)
+	rb.VirtualFlush()
+	if !rb.IsEmpty() || rb.Length() != 0 || rb.VirtualLength() != 0 {
+		t.Fatal(rb.Length(), rb.VirtualLength())
+	}
+
+	// 写满并且读指针不在 0 处
+	_, _ = rb.Write([]byte("ab"))
+	_, _ = rb.Read(buf[:1])
+	_, _ = rb.Write([]byte("cdefgh"))
+	if !rb.IsFull() {
+		t.Fatal(rb.Length())
+	}
+	n, _ = rb.VirtualRead(buf)
+	if n != 7 || !bytes.Equal(buf, []byte("bcdefgh")) {
+		t.Fatalf("expect bcdefgh but got %s", buf[:n])
+	}
+	rb.VirtualFlush()
+	if !rb.IsEmpty() || rb.Length() != 0 {
+		t.Fatal(rb.Length())
+	}
+}
+
 func TestRingBuffer_PeekUintXX(t *testing.T) {
 	rb := New(1024)
 	_ = rb.WriteByte(0x01)
@@ -671,3 +765,116 @@ func TestRingBuffer_Bytes(t *testing.T) {
 		t.Fatalf("except %s, but got %s", except, actual)
 	}
 }
+
+func TestRingBuffer_PeekAt(t *testing.T) {
+	rb := New(16)
+	_, _ = rb.Write([]byte(strings.Repeat("abcd", 3)))
+	rb.Retrieve(8)
+	_, _ = rb.Write([]byte("12345678"))
+
+	// r.r=8, r.w=4, 可读数据 abcd12345678
+	first, end := rb.PeekAt(2, 4)
+	if !bytes.Equal(first, []byte("cd12")) || len(end) != 0 {
+		t.Fatalf("expect cd12 but got %s %s. r.w=%d, r.r=%d", first, end, rb.w, rb.r)
+	}
+
+	first, end = rb.PeekAt(6, 4)
+	if !bytes.Equal(first, []byte("34")) || !bytes.Equal(end, []byte("56")) {
+		t.Fatalf("expect 34 56 but got %s %s. r.w=%d, r.r=%d", first, end, rb.w, rb.r)
+	}
+
+	first, end = rb.PeekAt(10, 100)
+	if !bytes.Equal(first, []byte("78")) || len(end) != 0 {
+		t.Fatalf("expect 78 but got %s %s. r.w=%d, r.r=%d", first, end, rb.w, rb.r)
+	}
+
+	first, end = rb.PeekAt(12, 1)
+	if len(first) != 0 || len(end) != 0 {
+		t.Fatalf("expect empty but got %s %s", first, end)
+	}
+	first, end = rb.PeekAt(-1, 1)
+	if len(first) != 0 || len(end) != 0 {
+		t.Fatalf("expect empty but got %s %s", first, end)
+	}
+	if rb.Length() != 12 {
+		t.Fatalf("expect len 12 bytes but got %d", rb.Length())
+	}
+}
+
+func TestRingBuffer_PeekUintXXAt(t *testing.T) {
+	rb := New(16)
+	_, _ = rb.Write(make([]byte, 12))
+	rb.Retrieve(11)
+
+	toWrite := make([]byte, 15)
+	toWrite[0] = 0x01
+	binary.BigEndian.PutUint16(toWrite[1:], 100)
+	binary.BigEndian.PutUint32(toWrite[3:], 200)
+	binary.BigEndian.PutUint64(toWrite[7:], 300)
+	_, _ = rb.Write(toWrite)
+
+	if v := rb.PeekUint8At(1); v != 0x01 {
+		t.Fatal(v)
+	}
+	if v := rb.PeekUint16At(2); v != 100 {
+		t.Fatal(v)
+	}
+	if v := rb.PeekUint32At(4); v != 200 {
+		t.Fatal(v)
+	}
+	if v := rb.PeekUint64At(8); v != 300 {
+		t.Fatal(v)
+	}
+	if v := rb.PeekUint64At(9); v != 0 {
+		t.Fatal(v)
+	}
+	if rb.Length() != 16 {
+		t.Fatal(rb.Length())
+	}
+}
+
+func TestRingBuffer_ReadAt(t *testing.T) {
+	rb := New(8)
+	_, _ = rb.Write([]byte("xxxxxx"))
+	rb.Retrieve(5)
+	_, _ = rb.Write([]byte("abcdefg"))
+
+	// 可读数据 xabcdefg，跨越了环尾
+	buf := make([]byte, 4)
+	n, err := rb.ReadAt(buf, 2)
+	if n != 4 || err != nil {
+		t.Fatalf("expect read 4 bytes but got %d, err %v", n, err)
+	}
+	if !bytes.Equal(buf, []byte("bcde")) {
+		t.Fatalf("expect bcde but got %s", buf)
+	}
+
+	n, err = rb.ReadAt(buf, 6)
+	if n != 2 || err != io.EOF {
+		t.Fatalf("expect read 2 bytes and EOF but got %d, err %v", n, err)
+	}
+	if !bytes.Equal(buf[:n], []byte("fg")) {
+		t.Fatalf("expect fg but got %s", buf[:n])
+	}
+
+	n, err = rb.ReadAt(buf, 8)
+	if n != 0 || err != io.EOF {
+		t.Fatalf("expect EOF but got %d, err %v", n, err)
+	}
+	_, err = rb.ReadAt(buf, -1)
+	if err != ErrNegativeOffset {
+		t.Fatalf("expect ErrNegativeOffset but got %v", err)
+	}
+	if rb.Length() != 8 {
+		t.Fatalf("expect len 8 bytes but got %d", rb.Length())
+	}
+
+	sr := io.NewSectionReader(rb, 1, 7)
+	all := make([]byte, 7)
+	if _, err = io.ReadFull(sr, all); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(all, []byte("abcdefg")) {
+		t.Fatalf("expect abcdefg but got %s", all)
+	}
+}
@@ -0,0 +1,54 @@
+package ringbuffer
+
+import "crypto/cipher"
+
+// Transform 按顺序访问可读数据开头的 n 个字节，fn 可以原地修改 seg
+// offset 为 seg 相对读指针的偏移，数据跨越环尾时 fn 会被调用两次，不会移动读指针
+func (r *RingBuffer) Transform(n int, fn func(seg []byte, offset int)) {
+	r.checkUse()
+	r.TransformAt(0, n, fn)
+}
+
+// TransformAt 同 Transform，从读指针偏移 offset 处开始
+func (r *RingBuffer) TransformAt(offset, n int, fn func(seg []byte, offset int)) {
+	r.checkUse()
+	first, end := r.PeekAt(offset, n)
+	if len(first) > 0 {
+		fn(first, offset)
+	}
+	if len(end) > 0 {
+		fn(end, offset+len(first))
+	}
+}
+
+// XORKeyStream 使用 stream 原地加（解）密可读数据开头的 n 个字节，例如 AES-CTR:
+//
+//	block, _ := aes.NewCipher(key)
+//	rb.XORKeyStream(n, cipher.NewCTR(block, iv))
+func (r *RingBuffer) XORKeyStream(n int, stream cipher.Stream) {
+	r.checkUse()
+	r.Transform(n, func(seg []byte, _ int) {
+		stream.XORKeyStream(seg, seg)
+	})
+}
+
+// XOR 使用循环的 key 原地异或可读数据开头的 n 个字节，key 从 key[0] 开始
+func (r *RingBuffer) XOR(n int, key []byte) {
+	r.checkUse()
+	r.XORAt(0, n, key)
+}
+
+// XORAt 同 XOR，从读指针偏移 offset 处开始，偏移 offset 处的字节对应 key[0]
+func (r *RingBuffer) XORAt(offset, n int, key []byte) {
+	r.checkUse()
+	if len(key) == 0 {
+		return
+	}
+
+	r.TransformAt(offset, n, func(seg []byte, segOffset int) {
+		pos := segOffset - offset
+		for i := range seg {
+			seg[i] ^= key[(pos+i)%len(key)]
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestRingBuffer_Transform(t *testing.T) {
+	rb := newWrapped("abcdefgh", 3)
+
+	var offsets []int
+	rb.Transform(6, func(seg []byte, offset int) {
+		offsets = append(offsets, offset)
+		for i := range seg {
+			seg[i] -= 'a' - 'A'
+		}
+	})
+	if len(offsets) != 2 || offsets[0] != 0 || offsets[1] != 3 {
+		t.Fatalf("unexpected offsets %v", offsets)
+	}
+	if string(rb.Bytes()) != "ABCDEFgh" {
+		t.Fatalf("expect ABCDEFgh but got %s", rb.Bytes())
+	}
+
+	offsets = offsets[:0]
+	rb.TransformAt(6, 10, func(seg []byte, offset int) {
+		offsets = append(offsets, offset)
+		seg[0] = 'X'
+	})
+	if len(offsets) != 1 || offsets[0] != 6 || string(rb.Bytes()) != "ABCDEFXh" {
+		t.Fatalf("unexpected %v %s", offsets, rb.Bytes())
+	}
+	if rb.Length() != 8 {
+		t.Fatalf("expect len 8 bytes but got %d", rb.Length())
+	}
+}
+
+func TestRingBuffer_XOR(t *testing.T) {
+	data := "hello ring buffer"
+	key := []byte{0x12, 0x34, 0x56}
+	rb := newWrapped(data, 5)
+
+	rb.XOR(len(data), key)
+	expect := []byte(data)
+	for i := range expect {
+		expect[i] ^= key[i%len(key)]
+	}
+	if !bytes.Equal(rb.Bytes(), expect) {
+		t.Fatalf("expect %x but got %x", expect, rb.Bytes())
+	}
+
+	rb.XOR(len(data), key)
+	if string(rb.Bytes()) != data {
+		t.Fatalf("expect %s but got %s", data, rb.Bytes())
+	}
+
+	// 从偏移处开始，key 依然从 key[0] 开始
+	rb.XORAt(4, 10, key)
+	expect = []byte(data)
+	for i := 0; i < 10; i++ {
+		expect[4+i] ^= key[i%len(key)]
+	}
+	if !bytes.Equal(rb.Bytes(), expect) {
+		t.Fatalf("expect %x but got %x", expect, rb.Bytes())
+	}
+}
+
+func TestRingBuffer_XORKeyStream(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	iv := bytes.Repeat([]byte{2}, aes.BlockSize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("AES-CTR keystream across the wrap point of a ring buffer")
+	expect := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(expect, data)
+
+	rb := newWrapped(string(data), 21)
+	rb.XORKeyStream(len(data), cipher.NewCTR(block, iv))
+	if !bytes.Equal(rb.Bytes(), expect) {
+		t.Fatalf("expect %x but got %x", expect, rb.Bytes())
+	}
+}
@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"unicode/utf8"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+// Frame 一个完整的帧，Payload 已经去掉掩码
+type Frame struct {
+	Header
+	Payload []byte
+}
+
+// Decoder 解码帧并合并分片消息
+//
+// Decoder 带有状态，每个连接需要使用单独的实例
+type Decoder struct {
+	// MaxFrameLength 单个帧 payload 的最大长度，为 0 时不限制
+	MaxFrameLength int64
+	// MaxMessageLength 合并分片后消息的最大长度，为 0 时不限制
+	MaxMessageLength int64
+
+	fragmented bool
+	opcode     Opcode
+	message    []byte
+}
+
+// NextFrame 从 rb 中取出一个完整的帧，payload 在 rb 中原地去掉掩码后拷贝出来，并移动读指针
+// 数据不完整时返回 ErrNeedMore，不移动读指针
+func (d *Decoder) NextFrame(rb *ringbuffer.RingBuffer) (f Frame, err error) {
+	h, n, err := ReadHeader(rb)
+	if err != nil {
+		return f, err
+	}
+	if d.MaxFrameLength > 0 && h.Length > d.MaxFrameLength {
+		return f, ErrTooLarge
+	}
+	if int64(rb.Length()-n) < h.Length {
+		return f, ErrNeedMore
+	}
+
+	length := int(h.Length)
+	if h.Masked {
+		maskInRing(rb, n, length, h.Mask)
+	}
+	rb.Retrieve(n)
+	f.Header = h
+	f.Payload = make([]byte, length)
+	_, _ = rb.Read(f.Payload)
+	return f, nil
+}
+
+// ReadMessage 从 rb 中读取一个完整的消息
+// 控制帧（Close、Ping、Pong）可能穿插在分片之间，会被立即返回；
+// 数据帧的分片会被合并，直到收到 FIN 才返回 OpText 或 OpBinary 的完整消息
+// 数据不完整时返回 ErrNeedMore，已经收到的分片保存在 Decoder 中
+func (d *Decoder) ReadMessage(rb *ringbuffer.RingBuffer) (op Opcode, payload []byte, err error) {
+	for {
+		f, err := d.NextFrame(rb)
+		if err != nil {
+			return 0, nil, err
+		}
+		if f.Rsv != 0 {
+			return 0, nil, ErrProtocol
+		}
+
+		switch f.Opcode {
+		case OpClose, OpPing, OpPong:
+			return f.Opcode, f.Payload, nil
+		case OpText, OpBinary:
+			if d.fragmented {
+				return 0, nil, ErrProtocol
+			}
+			if f.Fin {
+				return f.Opcode, f.Payload, d.validate(f.Opcode, f.Payload)
+			}
+			d.fragmented = true
+			d.opcode = f.Opcode
+			d.message = f.Payload
+		case OpContinuation:
+			if !d.fragmented {
+				return 0, nil, ErrProtocol
+			}
+			if d.MaxMessageLength > 0 && int64(len(d.message)+len(f.Payload)) > d.MaxMessageLength {
+				return 0, nil, ErrTooLarge
+			}
+			d.message = append(d.message, f.Payload...)
+			if f.Fin {
+				op, payload = d.opcode, d.message
+				d.fragmented = false
+				d.message = nil
+				return op, payload, d.validate(op, payload)
+			}
+		default:
+			return 0, nil, ErrProtocol
+		}
+	}
+}
+
+func (d *Decoder) validate(op Opcode, payload []byte) error {
+	if d.MaxMessageLength > 0 && int64(len(payload)) > d.MaxMessageLength {
+		return ErrTooLarge
+	}
+	if op == OpText && !utf8.Valid(payload) {
+		return ErrProtocol
+	}
+	return nil
+}
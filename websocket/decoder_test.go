@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+func TestDecoder_NextFrame(t *testing.T) {
+	rb := ringbuffer.New(64)
+	_, _ = rb.Write(make([]byte, 50))
+	_, _ = rb.Read(make([]byte, 50))
+
+	payload := []byte("masked payload across the wrap point")
+	WriteFrame(rb, Header{Fin: true, Opcode: OpBinary, Masked: true, Mask: [4]byte{0xde, 0xad, 0xbe, 0xef}}, payload)
+
+	if first, end := rb.PeekAll(); len(first) == 0 || len(end) == 0 || rb.Capacity() != 64 {
+		t.Fatalf("expect wrapped data but got %d %d", len(first), len(end))
+	}
+
+	var d Decoder
+	f, err := d.NextFrame(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(f.Payload, payload) {
+		t.Fatalf("expect %q but got %q", payload, f.Payload)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect empty but got len %d", rb.Length())
+	}
+
+	WriteFrame(rb, Header{Fin: true, Opcode: OpBinary}, payload)
+	d.MaxFrameLength = 8
+	if _, err = d.NextFrame(rb); err != ErrTooLarge {
+		t.Fatalf("expect ErrTooLarge but got %v", err)
+	}
+}
+
+func TestDecoder_ReadMessage(t *testing.T) {
+	rb := ringbuffer.New(16)
+	mask := [4]byte{1, 2, 3, 4}
+	WriteFrame(rb, Header{Opcode: OpText, Masked: true, Mask: mask}, []byte("Hel"))
+	WriteFrame(rb, Header{Fin: true, Opcode: OpPing, Masked: true, Mask: mask}, []byte("ping"))
+	WriteFrame(rb, Header{Opcode: OpContinuation, Masked: true, Mask: mask}, []byte("lo, "))
+
+	var d Decoder
+	op, payload, err := d.ReadMessage(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op != OpPing || string(payload) != "ping" {
+		t.Fatalf("expect ping but got %v %q", op, payload)
+	}
+
+	_, _, err = d.ReadMessage(rb)
+	if err != ErrNeedMore {
+		t.Fatalf("expect ErrNeedMore but got %v", err)
+	}
+
+	WriteFrame(rb, Header{Fin: true, Opcode: OpContinuation, Masked: true, Mask: mask}, []byte("世界"))
+	op, payload, err = d.ReadMessage(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op != OpText || string(payload) != "Hello, 世界" {
+		t.Fatalf("expect text message but got %v %q", op, payload)
+	}
+
+	WriteFrame(rb, Header{Fin: true, Opcode: OpContinuation}, []byte("x"))
+	if _, _, err = d.ReadMessage(rb); err != ErrProtocol {
+		t.Fatalf("expect ErrProtocol but got %v", err)
+	}
+
+	rb.RetrieveAll()
+	WriteFrame(rb, Header{Fin: true, Opcode: OpText}, []byte{0xff})
+	if _, _, err = d.ReadMessage(rb); err != ErrProtocol {
+		t.Fatalf("expect ErrProtocol but got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Package websocket 基于 RingBuffer 的 RFC 6455 WebSocket 帧编解码
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+var (
+	// ErrNeedMore 数据不完整，需要等待更多数据
+	ErrNeedMore = errors.New("websocket: need more data")
+	// ErrProtocol 协议错误，需要关闭连接
+	ErrProtocol = errors.New("websocket: protocol error")
+	// ErrTooLarge 帧或者消息超过长度限制
+	ErrTooLarge = errors.New("websocket: frame or message too large")
+)
+
+// Opcode 帧类型
+type Opcode byte
+
+const (
+	OpContinuation Opcode = 0x0
+	OpText         Opcode = 0x1
+	OpBinary       Opcode = 0x2
+	OpClose        Opcode = 0x8
+	OpPing         Opcode = 0x9
+	OpPong         Opcode = 0xA
+)
+
+// IsControl 是否是控制帧
+func (op Opcode) IsControl() bool {
+	return op&0x8 != 0
+}
+
+// maxControlPayloadLength 控制帧 payload 的最大长度
+const maxControlPayloadLength = 125
+
+// maxHeaderLength 帧头最大长度：2 + 8 字节长度 + 4 字节掩码
+const maxHeaderLength = 14
+
+// Header 帧头
+type Header struct {
+	Fin    bool
+	Rsv    byte // RSV1-3，位于低 3 位
+	Opcode Opcode
+	Masked bool
+	Mask   [4]byte
+	Length int64 // payload 长度
+}
+
+// ReadHeader 解析 rb 中的帧头，不移动读指针，n 为帧头的长度
+// 数据不完整时返回 ErrNeedMore
+func ReadHeader(rb *ringbuffer.RingBuffer) (h Header, n int, err error) {
+	var tmp [maxHeaderLength]byte
+	first, end := rb.Peek(maxHeaderLength)
+	length := copy(tmp[:], first)
+	length += copy(tmp[length:], end)
+	if length < 2 {
+		return h, 0, ErrNeedMore
+	}
+
+	h.Fin = tmp[0]&0x80 != 0
+	h.Rsv = (tmp[0] >> 4) & 0x7
+	h.Opcode = Opcode(tmp[0] & 0xf)
+	h.Masked = tmp[1]&0x80 != 0
+
+	n = 2
+	switch l := tmp[1] & 0x7f; l {
+	case 126:
+		n += 2
+		if length < n {
+			return h, 0, ErrNeedMore
+		}
+		h.Length = int64(binary.BigEndian.Uint16(tmp[2:]))
+	case 127:
+		n += 8
+		if length < n {
+			return h, 0, ErrNeedMore
+		}
+		l := binary.BigEndian.Uint64(tmp[2:])
+		if l>>63 != 0 {
+			return h, 0, ErrProtocol
+		}
+		h.Length = int64(l)
+	default:
+		h.Length = int64(l)
+	}
+
+	if h.Masked {
+		if length < n+4 {
+			return h, 0, ErrNeedMore
+		}
+		copy(h.Mask[:], tmp[n:n+4])
+		n += 4
+	}
+
+	if h.Opcode.IsControl() && (!h.Fin || h.Length > maxControlPayloadLength) {
+		return h, 0, ErrProtocol
+	}
+	return h, n, nil
+}
+
+// WriteFrame 将一个帧直接写入 rb，h.Length 会被忽略，以 payload 的长度为准
+// h.Masked 为 true 时会使用 h.Mask 在 rb 中原地对 payload 加掩码，payload 本身不会被修改
+// 会先预留整个帧的空间，预留失败（例如超出 Budget）时返回错误，rb 中不会留下不完整的帧
+func WriteFrame(rb *ringbuffer.RingBuffer, h Header, payload []byte) error {
+	var tmp [maxHeaderLength]byte
+	tmp[0] = byte(h.Opcode)&0xf | (h.Rsv&0x7)<<4
+	if h.Fin {
+		tmp[0] |= 0x80
+	}
+
+	n := 2
+	switch l := len(payload); {
+	case l < 126:
+		tmp[1] = byte(l)
+	case l <= 0xffff:
+		tmp[1] = 126
+		binary.BigEndian.PutUint16(tmp[2:], uint16(l))
+		n += 2
+	default:
+		tmp[1] = 127
+		binary.BigEndian.PutUint64(tmp[2:], uint64(l))
+		n += 8
+	}
+
+	if h.Masked {
+		tmp[1] |= 0x80
+		copy(tmp[n:], h.Mask[:])
+		n += 4
+	}
+
+	if err := rb.Grow(n + len(payload)); err != nil {
+		return err
+	}
+
+	_, _ = rb.Write(tmp[:n])
+	_, _ = rb.Write(payload)
+	if h.Masked {
+		maskInRing(rb, rb.Length()-len(payload), len(payload), h.Mask)
+	}
+	return nil
+}
+
+// maskInRing 对 rb 中偏移 offset 处的 n 个字节原地加（解）掩码
+func maskInRing(rb *ringbuffer.RingBuffer, offset, n int, key [4]byte) {
+	rb.XORAt(offset, n, key[:])
+}
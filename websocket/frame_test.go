@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Allenxuxu/ringbuffer"
+)
+
+func TestWriteFrame(t *testing.T) {
+	rb := ringbuffer.New(8)
+
+	// RFC 6455 5.7 的示例
+	WriteFrame(rb, Header{Fin: true, Opcode: OpText}, []byte("Hello"))
+	expect := []byte{0x81, 0x05, 0x48, 0x65, 0x6c, 0x6c, 0x6f}
+	if !bytes.Equal(rb.Bytes(), expect) {
+		t.Fatalf("expect %x but got %x", expect, rb.Bytes())
+	}
+
+	rb.RetrieveAll()
+	payload := []byte("Hello")
+	WriteFrame(rb, Header{Fin: true, Opcode: OpText, Masked: true, Mask: [4]byte{0x37, 0xfa, 0x21, 0x3d}}, payload)
+	expect = []byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58}
+	if !bytes.Equal(rb.Bytes(), expect) {
+		t.Fatalf("expect %x but got %x", expect, rb.Bytes())
+	}
+	if string(payload) != "Hello" {
+		t.Fatalf("payload should not be modified, got %q", payload)
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	for _, l := range []int{0, 125, 126, 0xffff, 0x10000} {
+		rb := ringbuffer.New(16)
+		WriteFrame(rb, Header{Opcode: OpBinary, Masked: true, Mask: [4]byte{1, 2, 3, 4}}, make([]byte, l))
+
+		h, n, err := ReadHeader(rb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Fin || h.Opcode != OpBinary || !h.Masked || h.Mask != [4]byte{1, 2, 3, 4} || h.Length != int64(l) {
+			t.Fatalf("unexpected header %+v", h)
+		}
+		if n+l != rb.Length() {
+			t.Fatalf("expect header len %d but got %d", rb.Length()-l, n)
+		}
+
+		first, _ := rb.Peek(n - 1)
+		partial := ringbuffer.New(16)
+		_, _ = partial.Write(first)
+		if _, _, err = ReadHeader(partial); err != ErrNeedMore {
+			t.Fatalf("expect ErrNeedMore but got %v", err)
+		}
+	}
+
+	rb := ringbuffer.New(16)
+	WriteFrame(rb, Header{Opcode: OpPing}, nil)
+	if _, _, err := ReadHeader(rb); err != ErrProtocol {
+		t.Fatalf("expect ErrProtocol but got %v", err)
+	}
+}
+
+func TestWriteFrameBudgetExceeded(t *testing.T) {
+	rb, err := ringbuffer.NewWithBudget(8, ringbuffer.NewBudget(16, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = WriteFrame(rb, Header{Fin: true, Opcode: OpBinary, Masked: true, Mask: [4]byte{1, 2, 3, 4}}, make([]byte, 20))
+	if err != ringbuffer.ErrBudgetExceeded {
+		t.Fatalf("expect ErrBudgetExceeded but got %v", err)
+	}
+	if !rb.IsEmpty() {
+		t.Fatalf("expect nothing written but got %x", rb.Bytes())
+	}
+}